@@ -0,0 +1,16 @@
+data "gpg_verified_checksums" "provider_release" {
+  checksums_path = "${path.module}/dist/terraform-provider-example_1.0.0_SHA256SUMS"
+  signature_path = "${path.module}/dist/terraform-provider-example_1.0.0_SHA256SUMS.sig"
+  public_keys    = [file("${path.module}/keys/release-signing-key.asc")]
+}
+
+resource "terraform_data" "provider_binary" {
+  input = filesha256("${path.module}/dist/terraform-provider-example_1.0.0_linux_amd64.zip")
+
+  lifecycle {
+    precondition {
+      condition     = filesha256("${path.module}/dist/terraform-provider-example_1.0.0_linux_amd64.zip") == data.gpg_verified_checksums.provider_release.hashes["terraform-provider-example_1.0.0_linux_amd64.zip"]
+      error_message = "The provider binary does not match the signed checksums."
+    }
+  }
+}
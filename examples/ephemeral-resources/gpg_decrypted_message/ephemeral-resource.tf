@@ -0,0 +1,35 @@
+ephemeral "gpg_decrypted_message" "database_password" {
+  ciphertext  = file("${path.module}/secrets/database_password.asc")
+  private_key = var.private_key
+  passphrase  = var.passphrase
+}
+
+resource "aws_db_instance" "this" {
+  identifier          = "example"
+  instance_class      = "db.t3.micro"
+  engine              = "postgres"
+  allocated_storage   = 20
+  username            = "admin"
+  password_wo         = ephemeral.gpg_decrypted_message.database_password.content
+  password_wo_version = 1
+}
+
+# Decrypt the secret access key that AWS encrypted to the public key in base64 format.
+resource "gpg_key_pair" "ci" {
+  identities = [{
+    name  = "CI"
+    email = "ci@example.com"
+  }]
+  passphrase = var.passphrase
+}
+
+resource "aws_iam_access_key" "ci" {
+  user    = "ci"
+  pgp_key = gpg_key_pair.ci.public_key_base64
+}
+
+ephemeral "gpg_decrypted_message" "secret_access_key" {
+  ciphertext_base64 = aws_iam_access_key.ci.encrypted_secret
+  private_key       = gpg_key_pair.ci.private_key
+  passphrase        = var.passphrase
+}
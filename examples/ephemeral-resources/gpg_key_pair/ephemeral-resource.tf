@@ -0,0 +1,17 @@
+ephemeral "random_password" "passphrase" {
+  length = 32
+}
+
+ephemeral "gpg_key_pair" "this" {
+  identities = [{
+    name  = "John Doe"
+    email = "john.doe@example.com"
+  }]
+  passphrase = ephemeral.random_password.passphrase.result
+}
+
+resource "aws_secretsmanager_secret_version" "private_key" {
+  secret_id                = aws_secretsmanager_secret.private_key.id
+  secret_string_wo         = ephemeral.gpg_key_pair.this.private_key
+  secret_string_wo_version = 1
+}
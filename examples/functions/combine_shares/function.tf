@@ -0,0 +1,4 @@
+output "private_key" {
+  value     = provider::gpg::combine_shares(var.decrypted_shares, gpg_key_shares.this.fingerprint)
+  sensitive = true
+}
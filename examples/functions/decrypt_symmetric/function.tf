@@ -0,0 +1,4 @@
+output "plaintext" {
+  value     = provider::gpg::decrypt_symmetric(var.ciphertext, var.passphrase)
+  sensitive = true
+}
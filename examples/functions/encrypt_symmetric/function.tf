@@ -0,0 +1,7 @@
+output "ciphertext" {
+  value = provider::gpg::encrypt_symmetric("database password", var.passphrase, {
+    cipher    = "aes256"
+    aead_mode = "ocb"
+    s2k_mode  = "argon2"
+  })
+}
@@ -0,0 +1,4 @@
+resource "local_sensitive_file" "laptop_key" {
+  filename = "${path.module}/laptop-key.asc"
+  content  = provider::gpg::export_secret_subkeys(file("${path.module}/release-key.asc"))
+}
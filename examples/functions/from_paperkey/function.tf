@@ -0,0 +1,4 @@
+output "private_key" {
+  value     = provider::gpg::from_paperkey(file("${path.module}/paperkey.txt"), gpg_key_pair.this.public_key)
+  sensitive = true
+}
@@ -0,0 +1,16 @@
+resource "gpg_key_pair" "this" {
+  identities = [{
+    name  = "John Doe"
+    email = "john.doe@example.com"
+  }]
+  passphrase = "topsecret"
+  notations = [{
+    name  = "team@example.com"
+    value = "platform"
+  }]
+  preferred_keyserver = "hkps://keys.openpgp.org"
+}
+
+output "notations" {
+  value = provider::gpg::inspect_key(gpg_key_pair.this.public_key).notations
+}
@@ -0,0 +1,7 @@
+locals {
+  openpgpkey = provider::gpg::openpgpkey_record(gpg_key_pair.example.public_key, "john.doe@example.com")
+}
+
+output "openpgpkey_zone_record" {
+  value = "${local.openpgpkey.name}. 3600 IN OPENPGPKEY ${local.openpgpkey.rdata}"
+}
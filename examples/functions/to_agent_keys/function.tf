@@ -0,0 +1,15 @@
+resource "gpg_key_pair" "this" {
+  identities = [{
+    name  = "John Doe"
+    email = "john.doe@example.com"
+  }]
+  passphrase = "topsecret"
+}
+
+resource "local_sensitive_file" "agent_keys" {
+  for_each = provider::gpg::to_agent_keys(gpg_key_pair.this.private_key, gpg_key_pair.this.passphrase)
+
+  filename        = "${path.module}/gnupg/private-keys-v1.d/${each.key}.key"
+  content         = each.value
+  file_permission = "0600"
+}
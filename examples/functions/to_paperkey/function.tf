@@ -0,0 +1,4 @@
+resource "local_sensitive_file" "paperkey" {
+  filename = "${path.module}/paperkey.txt"
+  content  = provider::gpg::to_paperkey(gpg_key_pair.this.private_key)
+}
@@ -0,0 +1,12 @@
+resource "gpg_key_pair" "this" {
+  identities = [{
+    name  = "John Doe"
+    email = "john.doe@example.com"
+  }]
+  passphrase            = "topsecret"
+  authentication_subkey = true
+}
+
+output "authorized_key" {
+  value = provider::gpg::to_ssh_public_key(gpg_key_pair.this.public_key)
+}
@@ -0,0 +1,11 @@
+provider "gpg" {
+  default_profile   = "gnupg"
+  default_expiry    = "17520h"
+  default_keyserver = "hkps://keyserver.ubuntu.com"
+  default_s2k = {
+    mode          = "argon2"
+    argon2_passes = 3
+  }
+  default_comment  = "managed by terraform"
+  default_wkd_base = "https://openpgpkey.example.com/.well-known/openpgpkey/example.com"
+}
@@ -0,0 +1,21 @@
+resource "gpg_key_pair" "recipient" {
+  identities = [{
+    name  = "John Doe"
+    email = "john.doe@example.com"
+  }]
+  passphrase = "topsecret"
+}
+
+resource "gpg_encrypted_message" "this" {
+  content     = "database password"
+  public_keys = [gpg_key_pair.recipient.public_key]
+}
+
+resource "gpg_encrypted_message" "shared" {
+  content    = "database password"
+  passphrase = var.shared_passphrase
+  aead_mode  = "ocb"
+  s2k = {
+    mode = "argon2"
+  }
+}
@@ -0,0 +1,22 @@
+resource "gpg_key_pair" "release" {
+  identities = [{
+    name  = "Release Signing"
+    email = "release@example.com"
+  }]
+  passphrase = "topsecret"
+}
+
+resource "gpg_home" "ci" {
+  path = "${path.module}/.gnupg"
+  private_keys = [{
+    private_key = gpg_key_pair.release.private_key
+    passphrase  = gpg_key_pair.release.passphrase
+  }]
+  ownertrust = {
+    (gpg_key_pair.release.fingerprint) = "ultimate"
+  }
+  gpg_conf = <<-EOT
+    pinentry-mode loopback
+    no-greeting
+  EOT
+}
@@ -0,0 +1,23 @@
+resource "gpg_key_pair" "ca" {
+  identities = [{
+    name  = "Example CA"
+    email = "ca@example.com"
+  }]
+  passphrase = "topsecret"
+}
+
+resource "gpg_key_pair" "engineer" {
+  identities = [{
+    name  = "Jane Doe"
+    email = "jane.doe@example.com"
+  }]
+  passphrase = "topsecret"
+}
+
+resource "gpg_key_certification" "this" {
+  signer_private_key = gpg_key_pair.ca.private_key
+  signer_passphrase  = gpg_key_pair.ca.passphrase
+  public_key         = gpg_key_pair.engineer.public_key
+  level              = "positive"
+  expiry             = "17520h"
+}
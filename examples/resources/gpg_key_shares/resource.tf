@@ -0,0 +1,14 @@
+resource "gpg_key_pair" "this" {
+  identities = [{
+    name  = "John Doe"
+    email = "john.doe@example.com"
+  }]
+  passphrase = "topsecret"
+}
+
+resource "gpg_key_shares" "this" {
+  private_key           = gpg_key_pair.this.private_key
+  share_count           = 3
+  threshold             = 2
+  custodian_public_keys = var.custodian_public_keys
+}
@@ -0,0 +1,34 @@
+resource "gpg_key_pair" "old" {
+  identities = [{
+    name  = "John Doe"
+    email = "john.doe@example.com"
+  }]
+  passphrase = "old secret"
+}
+
+resource "gpg_key_pair" "new" {
+  identities = [{
+    name  = "John Doe"
+    email = "john.doe@example.com"
+  }]
+  passphrase = "new secret"
+}
+
+resource "gpg_key_transition" "this" {
+  old_private_key = gpg_key_pair.old.private_key
+  old_passphrase  = gpg_key_pair.old.passphrase
+  new_private_key = gpg_key_pair.new.private_key
+  new_passphrase  = gpg_key_pair.new.passphrase
+  reason          = "Migration to a stronger key."
+  certify_new_key = true
+}
+
+# Publishing the certified key lets partners who trust the old key validate the new key.
+resource "gpg_keyserver_publication" "this" {
+  public_key = gpg_key_transition.this.certified_public_key
+  keyserver  = "hkps://keys.openpgp.org"
+}
+
+output "transition_statement" {
+  value = gpg_key_transition.this.statement
+}
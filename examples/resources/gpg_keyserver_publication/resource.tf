@@ -0,0 +1,12 @@
+resource "gpg_key_pair" "this" {
+  identities = [{
+    name  = "John Doe"
+    email = "john.doe@example.com"
+  }]
+  passphrase = "topsecret"
+}
+
+resource "gpg_keyserver_publication" "this" {
+  keyserver  = "hkps://keyserver.ubuntu.com"
+  public_key = gpg_key_pair.this.public_key
+}
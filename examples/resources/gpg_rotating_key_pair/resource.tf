@@ -0,0 +1,20 @@
+# The key pair is rotated by the first apply after `next_rotation`, e.g. a scheduled pipeline. The previous key pair
+# stays available for decrypting messages that were encrypted to it.
+resource "gpg_rotating_key_pair" "this" {
+  identities = [{
+    name  = "John Doe"
+    email = "john.doe@example.com"
+  }]
+  passphrase      = "topsecret"
+  rotation_period = "8760h"
+  keep_previous   = 2
+}
+
+resource "gpg_keyserver_publication" "this" {
+  public_key = gpg_rotating_key_pair.this.public_key
+  keyserver  = "hkps://keys.openpgp.org"
+}
+
+output "transition_statement" {
+  value = gpg_rotating_key_pair.this.transition_statement
+}
@@ -0,0 +1,22 @@
+resource "gpg_key_pair" "this" {
+  identities = [{
+    name  = "John Doe"
+    email = "john.doe@example.com"
+  }]
+  passphrase = "topsecret"
+}
+
+# Replacing the subkey, e.g. with `terraform apply -replace`, rotates it while the fingerprint of the key stays the
+# same. The replaced subkey is revoked on the keyserver.
+resource "gpg_subkey" "encryption" {
+  primary_private_key = gpg_key_pair.this.private_key
+  passphrase          = gpg_key_pair.this.passphrase
+  usage               = "encrypt"
+  expiry              = "8760h"
+  keyserver           = "hkps://keys.openpgp.org"
+}
+
+resource "gpg_keyserver_publication" "this" {
+  public_key = gpg_subkey.encryption.public_key
+  keyserver  = "hkps://keys.openpgp.org"
+}
@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"strings"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &CombineSharesFunction{}
+
+func NewCombineSharesFunction() function.Function {
+	return &CombineSharesFunction{}
+}
+
+type CombineSharesFunction struct{}
+
+func (f CombineSharesFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "combine_shares"
+}
+
+func (f CombineSharesFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Recover a private key from its shares",
+		MarkdownDescription: "Combines at least the threshold number of shares created by the `gpg_key_shares` resource " +
+			"and returns the private key in armored format. Shares that were encrypted to custodians have to be decrypted " +
+			"first. The function fails if the recovered key does not have the expected fingerprint.",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				ElementType:         types.StringType,
+				Name:                "shares",
+				MarkdownDescription: "Decrypted shares of the private key.",
+			},
+			function.StringParameter{
+				Name:                "fingerprint",
+				MarkdownDescription: "Expected fingerprint of the private key.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f CombineSharesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var shares []string
+	var fingerprint string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &shares, &fingerprint))
+
+	if resp.Error != nil {
+		return
+	}
+
+	keyShares := make([]keyShare, len(shares))
+	for i, share := range shares {
+		keyShare, err := parseKeyShare(share)
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Share %d is invalid: %s", i, err))
+			return
+		}
+		if !strings.EqualFold(keyShare.Fingerprint, fingerprint) {
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Share %d belongs to key %s, expected %s", i, keyShare.Fingerprint, fingerprint))
+			return
+		}
+		keyShares[i] = keyShare
+	}
+
+	secret, err := combineShares(keyShares)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Combining the shares failed with error: %s", err))
+		return
+	}
+	defer clear(secret)
+
+	key, err := gpgcrypto.NewKey(secret)
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("NewKey failed with error: %s", err))
+		return
+	}
+	if !strings.EqualFold(key.GetFingerprint(), fingerprint) {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Recovered key has fingerprint %s, expected %s", key.GetFingerprint(), fingerprint))
+		return
+	}
+
+	privateKey, err := key.Armor()
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Armor failed with error: %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, privateKey))
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &DecryptSymmetricFunction{}
+
+func NewDecryptSymmetricFunction() function.Function {
+	return &DecryptSymmetricFunction{}
+}
+
+type DecryptSymmetricFunction struct{}
+
+func (f DecryptSymmetricFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "decrypt_symmetric"
+}
+
+func (f DecryptSymmetricFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Decrypt a message with a passphrase",
+		MarkdownDescription: "Decrypts an armored message that was encrypted with a passphrase. The cipher, AEAD mode and S2K settings are read from the message.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "ciphertext",
+				MarkdownDescription: "Encrypted message in armored format.",
+			},
+			function.StringParameter{
+				Name:                "passphrase",
+				MarkdownDescription: "Passphrase the message was encrypted with.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f DecryptSymmetricFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var ciphertext, passphrase string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &ciphertext, &passphrase))
+
+	if resp.Error != nil {
+		return
+	}
+
+	handle, err := gpgcrypto.PGP().Decryption().Password([]byte(passphrase)).New()
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("New failed with error: %s", err))
+		return
+	}
+
+	result, err := handle.Decrypt([]byte(ciphertext), gpgcrypto.Armor)
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Decrypt failed with error: %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, string(result.Bytes())))
+}
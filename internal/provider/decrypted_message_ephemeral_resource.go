@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ ephemeral.EphemeralResource = &DecryptedMessageEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithValidateConfig = &DecryptedMessageEphemeralResource{}
+
+func NewDecryptedMessageEphemeralResource() ephemeral.EphemeralResource {
+	return &DecryptedMessageEphemeralResource{}
+}
+
+type DecryptedMessageEphemeralResource struct {
+}
+
+func (g DecryptedMessageEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_decrypted_message"
+}
+
+func (g DecryptedMessageEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "An ephemeral resource for decrypting a message without storing the plaintext in the plan or state, e.g. to pass it to write-only attributes of other resources.",
+		Attributes: map[string]schema.Attribute{
+			"ciphertext": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Encrypted message in armored format. Exactly one of `ciphertext` and `ciphertext_base64` must be set.",
+			},
+			"ciphertext_base64": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Encrypted message in base64 format, e.g. the `encrypted_secret` of `aws_iam_access_key` or the `encrypted_password` of `aws_iam_user_login_profile`. Exactly one of `ciphertext` and `ciphertext_base64` must be set.",
+			},
+			"private_key": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private key of a recipient in armored format. A locked key is unlocked with `passphrase`. At least one of `private_key` and `passphrase` must be set.",
+			},
+			"passphrase": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Passphrase for unlocking `private_key`, or for decrypting a message encrypted with a passphrase if `private_key` is not set. At least one of `private_key` and `passphrase` must be set.",
+			},
+			"content": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Decrypted plaintext.",
+			},
+		},
+	}
+}
+
+func (g DecryptedMessageEphemeralResource) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var data decryptedMessageModelV1
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Ciphertext.IsNull() == data.CiphertextBase64.IsNull() {
+		resp.Diagnostics.AddError("Invalid ciphertext", "Exactly one of ciphertext and ciphertext_base64 must be set.")
+	}
+
+	if data.PrivateKey.IsNull() && data.Passphrase.IsNull() {
+		resp.Diagnostics.AddError("Missing decryption key", "At least one of private_key and passphrase must be set.")
+	}
+}
+
+func (g DecryptedMessageEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data decryptedMessageModelV1
+
+	// Read Terraform config data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	builder := gpgcrypto.PGP().Decryption()
+	if !data.PrivateKey.IsNull() {
+		key, err := gpgcrypto.NewKeyFromArmored(data.PrivateKey.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("private_key"), "GPG decryption failed", fmt.Sprintf("NewKeyFromArmored failed with error: %s", err))
+			return
+		}
+
+		locked, err := key.IsLocked()
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("private_key"), "GPG decryption failed", fmt.Sprintf("IsLocked failed with error: %s", err))
+			return
+		}
+		if locked {
+			key, err = key.Unlock([]byte(data.Passphrase.ValueString()))
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("passphrase"), "GPG decryption failed", fmt.Sprintf("Unlock failed with error: %s", err))
+				return
+			}
+		}
+		defer key.ClearPrivateParams()
+
+		builder = builder.DecryptionKey(key)
+	} else {
+		builder = builder.Password([]byte(data.Passphrase.ValueString()))
+	}
+
+	handle, err := builder.New()
+	if err != nil {
+		resp.Diagnostics.AddError("GPG decryption failed", fmt.Sprintf("New failed with error: %s", err))
+		return
+	}
+
+	// Other providers, e.g. the AWS provider, return the binary message in base64 format.
+	ciphertextPath := path.Root("ciphertext")
+	ciphertext, encoding := []byte(data.Ciphertext.ValueString()), gpgcrypto.Armor
+	if !data.CiphertextBase64.IsNull() {
+		ciphertextPath, encoding = path.Root("ciphertext_base64"), gpgcrypto.Bytes
+		ciphertext, err = base64.StdEncoding.DecodeString(data.CiphertextBase64.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(ciphertextPath, "GPG decryption failed", fmt.Sprintf("DecodeString failed with error: %s", err))
+			return
+		}
+	}
+
+	result, err := handle.Decrypt(ciphertext, encoding)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(ciphertextPath, "GPG decryption failed", fmt.Sprintf("Decrypt failed with error: %s", err))
+		return
+	}
+
+	data.Content = types.StringValue(string(result.Bytes()))
+
+	// Save data into the ephemeral result
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+type decryptedMessageModelV1 struct {
+	Ciphertext       types.String `tfsdk:"ciphertext"`
+	CiphertextBase64 types.String `tfsdk:"ciphertext_base64"`
+	PrivateKey       types.String `tfsdk:"private_key"`
+	Passphrase       types.String `tfsdk:"passphrase"`
+	Content          types.String `tfsdk:"content"`
+}
@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccDecryptedMessageEphemeralResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+ephemeral "gpg_decrypted_message" "test" {
+  ciphertext = provider::gpg::encrypt_symmetric("database password", "shared secret")
+  passphrase = "wrong secret"
+}
+
+provider "echo" {
+  data = ephemeral.gpg_decrypted_message.test.content
+}
+
+resource "echo" "test" {}
+`,
+				ExpectError: regexp.MustCompile(`GPG decryption failed`),
+			},
+			{
+				Config: testAccDecryptedMessageEphemeralResourceConfig(),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("private_key"), knownvalue.StringExact("database password")),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("passphrase"), knownvalue.StringExact("shared secret")),
+				},
+			},
+		},
+	})
+}
+
+// TestAccDecryptedMessageEphemeralResource_base64 decrypts a binary message in base64 format like the encrypted secrets
+// returned by the AWS provider.
+func TestAccDecryptedMessageEphemeralResource_base64(t *testing.T) {
+	key, err := crypto.PGP().KeyGeneration().AddUserId("John Doe", "john.doe@example.com").New().GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey, err := key.Armor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := key.ToPublic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryption, err := crypto.PGP().Encryption().Recipient(publicKey).New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := encryption.Encrypt([]byte("secret access key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := base64.StdEncoding.EncodeToString(message.Bytes())
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccDecryptedMessageEphemeralResourceBase64Config(privateKey, fmt.Sprintf("ciphertext = %q\n  ciphertext_base64 = %q", ciphertext, ciphertext)),
+				ExpectError: regexp.MustCompile(`Exactly one of ciphertext and ciphertext_base64 must be set`),
+			},
+			{
+				Config:      testAccDecryptedMessageEphemeralResourceBase64Config(privateKey, `ciphertext_base64 = "not base64"`),
+				ExpectError: regexp.MustCompile(`DecodeString failed`),
+			},
+			{
+				Config: testAccDecryptedMessageEphemeralResourceBase64Config(privateKey, fmt.Sprintf("ciphertext_base64 = %q", ciphertext)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data"), knownvalue.StringExact("secret access key")),
+				},
+			},
+		},
+	})
+}
+
+func testAccDecryptedMessageEphemeralResourceBase64Config(privateKey string, ciphertext string) string {
+	return fmt.Sprintf(`
+ephemeral "gpg_decrypted_message" "test" {
+  %[2]s
+  private_key = %[1]q
+}
+
+provider "echo" {
+  data = ephemeral.gpg_decrypted_message.test.content
+}
+
+resource "echo" "test" {}
+`, privateKey, ciphertext)
+}
+
+func testAccDecryptedMessageEphemeralResourceConfig() string {
+	return `
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+}
+
+resource "gpg_encrypted_message" "test" {
+  content     = "database password"
+  public_keys = [gpg_key_pair.test.public_key]
+}
+
+ephemeral "gpg_decrypted_message" "private_key" {
+  ciphertext  = gpg_encrypted_message.test.ciphertext
+  private_key = gpg_key_pair.test.private_key
+  passphrase  = "top secret"
+}
+
+ephemeral "gpg_decrypted_message" "passphrase" {
+  ciphertext = provider::gpg::encrypt_symmetric("shared secret", "passphrase")
+  passphrase = "passphrase"
+}
+
+provider "echo" {
+  data = {
+    private_key = ephemeral.gpg_decrypted_message.private_key.content
+    passphrase  = ephemeral.gpg_decrypted_message.passphrase.content
+  }
+}
+
+resource "echo" "test" {}
+`
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"github.com/ProtonMail/gopenpgp/v3/constants"
+	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/ProtonMail/gopenpgp/v3/profile"
+	"io"
+	"time"
+)
+
+// deterministicCreationTime is the default creation time of keys derived from a seed, so that their fingerprints only
+// depend on the seed and the settings.
+var deterministicCreationTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// errDeterministicRSA is returned for RSA profiles, as the Go standard library does not derive RSA keys from a
+// custom random source.
+var errDeterministicRSA = errors.New("deterministic RSA keys are not supported")
+
+// generateDeterministicKey generates an unlocked key that reads all random values from random and is created at
+// creationTime.
+func generateDeterministicKey(keyProfile *profile.Custom, identities []identityModelV1, creationTime time.Time, lifetime int32, random io.Reader) (*gpgcrypto.Key, error) {
+	config := keyProfile.KeyGenerationConfig(constants.HighSecurity)
+	if config.PublicKeyAlgorithm() == packet.PubKeyAlgoRSA {
+		return nil, errDeterministicRSA
+	}
+	config.Rand = random
+	config.Time = func() time.Time { return creationTime }
+	config.KeyLifetimeSecs = uint32(lifetime)
+
+	entity, err := openpgp.NewEntity(identities[0].Name.ValueString(), "", identities[0].Email.ValueString(), config)
+	if err != nil {
+		return nil, err
+	}
+	for _, identity := range identities[1:] {
+		if err := entity.AddUserId(identity.Name.ValueString(), "", identity.Email.ValueString(), config); err != nil {
+			return nil, err
+		}
+	}
+	return gpgcrypto.NewKeyFromEntity(entity)
+}
+
+// lockDeterministicKey locks a copy of the key like PGPHandle.LockKey, but reads the salts and IVs from random.
+func lockDeterministicKey(keyProfile *profile.Custom, key *gpgcrypto.Key, passphrase []byte, random io.Reader) (*gpgcrypto.Key, error) {
+	locked, err := key.Copy()
+	if err != nil {
+		return nil, err
+	}
+
+	config := keyProfile.KeyEncryptionConfig()
+	config.Rand = random
+	if err := locked.GetEntity().EncryptPrivateKeys(passphrase, config); err != nil {
+		return nil, err
+	}
+	return locked, nil
+}
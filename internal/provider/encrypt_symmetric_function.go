@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/gopenpgp/v3/armor"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"io"
+	"strconv"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &EncryptSymmetricFunction{}
+
+func NewEncryptSymmetricFunction() function.Function {
+	return &EncryptSymmetricFunction{}
+}
+
+type EncryptSymmetricFunction struct{}
+
+func (f EncryptSymmetricFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "encrypt_symmetric"
+}
+
+func (f EncryptSymmetricFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Encrypt a message with a passphrase",
+		MarkdownDescription: "Encrypts the content with a passphrase and returns the message in armored format. " +
+			"Terraform requires functions to return the same result for the same arguments, so all random values " +
+			"(salts, session key and IVs) are derived from the passphrase, the content and the options. Encrypting the " +
+			"same content with the same passphrase and options therefore yields the same message, so anyone who " +
+			"sees two messages learns whether their contents are equal, e.g. whether a password was changed or " +
+			"reused. As the S2K salt is derived from the passphrase, anyone who can guess the content can also test " +
+			"guessed passphrases against the salt with a single HMAC, bypassing the S2K. Use the " +
+			"`gpg_encrypted_message` resource to get a randomized ciphertext that is kept stable in the state.\n\n" +
+			"The optional `options` map supports the keys `profile`, `cipher` (`aes128`, `aes192`, `aes256`), " +
+			"`aead_mode` (`eax`, `ocb`, `gcm`, `none`), `s2k_mode` (`iterated`, `argon2`), `s2k_count`, " +
+			"`argon2_passes`, `argon2_parallelism` and `argon2_memory`. Unset values are taken from the profile.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "content",
+				MarkdownDescription: "Plaintext to encrypt.",
+			},
+			function.StringParameter{
+				Name:                "passphrase",
+				MarkdownDescription: "Passphrase to encrypt the content with.",
+			},
+		},
+		VariadicParameter: function.MapParameter{
+			ElementType:         types.StringType,
+			Name:                "options",
+			MarkdownDescription: "At most one map of encryption options.",
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f EncryptSymmetricFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var content, passphrase string
+	var options []map[string]string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &content, &passphrase, &options))
+
+	if resp.Error != nil {
+		return
+	}
+
+	if len(options) > 1 {
+		resp.Error = function.NewArgumentFuncError(2, "At most one options map may be given.")
+		return
+	}
+	var option map[string]string
+	if len(options) == 1 {
+		option = options[0]
+	}
+
+	profileName, symmetric, err := parseSymmetricOptions(option)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(2, fmt.Sprintf("Invalid options: %s", err))
+		return
+	}
+
+	keyProfile, err := profileByName(profileName)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(2, fmt.Sprintf("Invalid options: %s", err))
+		return
+	}
+	symmetric.apply(keyProfile)
+
+	config := keyProfile.EncryptionConfig()
+	config.Rand = deterministicRand([]byte(passphrase), []byte(content), option)
+
+	var buf bytes.Buffer
+	plaintext, err := openpgp.SymmetricallyEncrypt(&buf, []byte(passphrase), nil, config)
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("SymmetricallyEncrypt failed with error: %s", err))
+		return
+	}
+	if _, err := plaintext.Write([]byte(content)); err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Write failed with error: %s", err))
+		return
+	}
+	if err := plaintext.Close(); err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Close failed with error: %s", err))
+		return
+	}
+
+	ciphertext, err := armor.ArmorPGPMessage(buf.Bytes())
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Armor failed with error: %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, ciphertext))
+}
+
+// parseSymmetricOptions converts the options map of the symmetric encryption function into a profile name and the
+// symmetric encryption settings.
+func parseSymmetricOptions(options map[string]string) (string, *symmetricModelV1, error) {
+	profileName := defaultProfileName
+	symmetric := &symmetricModelV1{
+		Cipher:   types.StringNull(),
+		AEADMode: types.StringNull(),
+	}
+	s2kConfig := &s2kModelV1{
+		Mode:              types.StringNull(),
+		Count:             types.Int64Null(),
+		Argon2Passes:      types.Int64Null(),
+		Argon2Parallelism: types.Int64Null(),
+		Argon2Memory:      types.Int64Null(),
+	}
+	s2kSet := false
+
+	for key, value := range options {
+		switch key {
+		case "profile":
+			profileName = value
+		case "cipher":
+			symmetric.Cipher = types.StringValue(value)
+		case "aead_mode":
+			symmetric.AEADMode = types.StringValue(value)
+		case "s2k_mode":
+			s2kConfig.Mode = types.StringValue(value)
+		case "s2k_count", "argon2_passes", "argon2_parallelism", "argon2_memory":
+			number, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return "", nil, fmt.Errorf("%s must be a number", key)
+			}
+			switch key {
+			case "s2k_count":
+				s2kConfig.Count = types.Int64Value(number)
+			case "argon2_passes":
+				s2kConfig.Argon2Passes = types.Int64Value(number)
+			case "argon2_parallelism":
+				s2kConfig.Argon2Parallelism = types.Int64Value(number)
+			case "argon2_memory":
+				s2kConfig.Argon2Memory = types.Int64Value(number)
+			}
+			s2kSet = true
+		default:
+			return "", nil, fmt.Errorf("unknown option %q", key)
+		}
+	}
+
+	if !s2kConfig.Mode.IsNull() {
+		symmetric.S2K = s2kConfig
+	} else if s2kSet {
+		return "", nil, fmt.Errorf("s2k_mode is required if other S2K options are set")
+	}
+
+	if err := symmetric.validate(); err != nil {
+		return "", nil, err
+	}
+	return profileName, symmetric, nil
+}
+
+// deterministicRand returns an AES-CTR key stream keyed with an HMAC of the content and the options under the
+// passphrase. It replaces the random source during encryption so that the function returns consistent results. The
+// length of the content is hashed first, so that different options and contents cannot yield the same input.
+func deterministicRand(passphrase []byte, content []byte, options map[string]string) io.Reader {
+	mac := hmac.New(sha256.New, passphrase)
+	_ = binary.Write(mac, binary.BigEndian, uint64(len(content)))
+	for _, key := range sortedKeys(options) {
+		_, _ = fmt.Fprintf(mac, "%q=%q\n", key, options[key])
+	}
+	mac.Write(content)
+
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		// A SHA-256 sum is always a valid AES-256 key.
+		panic(err)
+	}
+	return cipher.StreamReader{
+		S: cipher.NewCTR(block, make([]byte, aes.BlockSize)),
+		R: zeroReader{},
+	}
+}
+
+// zeroReader is an endless source of zero bytes.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
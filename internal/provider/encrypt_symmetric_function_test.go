@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccEncryptSymmetricFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+locals {
+  ciphertext = provider::gpg::encrypt_symmetric("hello", "top secret")
+}
+
+output "test" {
+  value = provider::gpg::decrypt_symmetric(local.ciphertext, "top secret")
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("test", "hello"),
+				),
+			},
+			{
+				Config: `
+locals {
+  ciphertext = provider::gpg::encrypt_symmetric("hello", "top secret", {
+    profile       = "rfc9580"
+    cipher        = "aes128"
+    aead_mode     = "gcm"
+    s2k_mode      = "argon2"
+    argon2_passes = 1
+    argon2_memory = 1024
+  })
+}
+
+output "test" {
+  value = provider::gpg::decrypt_symmetric(local.ciphertext, "top secret")
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("test", "hello"),
+				),
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::gpg::encrypt_symmetric("hello", "top secret", { cipher = "des" })
+}
+`,
+				ExpectError: regexp.MustCompile(`unknown cipher "des"`),
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::gpg::decrypt_symmetric(provider::gpg::encrypt_symmetric("hello", "top secret"), "wrong")
+}
+`,
+				ExpectError: regexp.MustCompile(`Decrypt failed`),
+			},
+		},
+	})
+}
+
+func TestDeterministicRand(t *testing.T) {
+	read := func(random io.Reader) []byte {
+		stream := make([]byte, 64)
+		if _, err := io.ReadFull(random, stream); err != nil {
+			t.Fatal(err)
+		}
+		return stream
+	}
+
+	stream := read(deterministicRand([]byte("top secret"), []byte("content"), map[string]string{"cipher": "aes256"}))
+	if !bytes.Equal(stream, read(deterministicRand([]byte("top secret"), []byte("content"), map[string]string{"cipher": "aes256"}))) {
+		t.Errorf("expected the same stream for the same inputs")
+	}
+	if bytes.Equal(stream, read(deterministicRand([]byte("other secret"), []byte("content"), map[string]string{"cipher": "aes256"}))) {
+		t.Errorf("expected another stream for another passphrase")
+	}
+	// The options are encoded like the start of the content, which must not yield the same stream.
+	if bytes.Equal(stream, read(deterministicRand([]byte("top secret"), []byte("\"cipher\"=\"aes256\"\ncontent"), nil))) {
+		t.Errorf("expected another stream for options moved into the content")
+	}
+}
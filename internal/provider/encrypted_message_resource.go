@@ -0,0 +1,463 @@
+package provider
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &EncryptedMessageResource{}
+var _ resource.ResourceWithConfigure = &EncryptedMessageResource{}
+var _ resource.ResourceWithValidateConfig = &EncryptedMessageResource{}
+var _ resource.ResourceWithModifyPlan = &EncryptedMessageResource{}
+
+func NewEncryptedMessageResource() resource.Resource {
+	return &EncryptedMessageResource{}
+}
+
+type EncryptedMessageResource struct {
+	// defaults holds the provider configuration, it is nil if the provider has not been configured.
+	defaults *GpgProviderModel
+}
+
+func (g *EncryptedMessageResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	defaults, ok := req.ProviderData.(*GpgProviderModel)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *GpgProviderModel, got %T.", req.ProviderData))
+		return
+	}
+	g.defaults = defaults
+}
+
+func (g EncryptedMessageResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_encrypted_message"
+}
+
+func (g EncryptedMessageResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A resource for encrypting a message once and keeping the ciphertext stable across plans",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Random ID of the message in hex format.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"content": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Plaintext to encrypt.",
+			},
+			"public_keys": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Public keys of the recipients in armored format. Changes to the keys that do not change their fingerprints do not re-encrypt the content. At least one of `public_keys` and `passphrase` must be set.",
+			},
+			"passphrase": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Passphrase to encrypt the content with in addition to or instead of the public keys. At least one of `public_keys` and `passphrase` must be set.",
+			},
+			"cipher": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: fmt.Sprintf("Symmetric cipher used to encrypt the content, one of %q. Defaults to the cipher of the provider's `default_profile`.", sortedKeys(ciphers)),
+			},
+			"aead_mode": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: fmt.Sprintf("AEAD mode used to encrypt the content, one of %q. Defaults to the AEAD mode of the provider's `default_profile`.", append(sortedKeys(aeadModes), aeadModeNone)),
+			},
+			"s2k": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "String-to-key settings used to derive the key from `passphrase`. Defaults to the settings of the provider's `default_profile`.",
+				Attributes: map[string]schema.Attribute{
+					"mode": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "S2K mode, either `iterated` or `argon2`.",
+					},
+					"count": schema.Int64Attribute{
+						Optional:            true,
+						MarkdownDescription: "Number of bytes hashed in `iterated` mode.",
+					},
+					"argon2_passes": schema.Int64Attribute{
+						Optional:            true,
+						MarkdownDescription: "Number of passes in `argon2` mode.",
+					},
+					"argon2_parallelism": schema.Int64Attribute{
+						Optional:            true,
+						MarkdownDescription: "Degree of parallelism in `argon2` mode.",
+					},
+					"argon2_memory": schema.Int64Attribute{
+						Optional:            true,
+						MarkdownDescription: "Memory in KiB used in `argon2` mode.",
+					},
+				},
+			},
+			"compress": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether to compress the content before encrypting it. Defaults to `false`.",
+			},
+			"hide_recipients": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether to omit the key IDs of the recipients from the message. Defaults to `false`.",
+			},
+			"content_hash": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "HMAC-SHA256 of the content keyed with `content_hash_key` in hex format, which detects changes of the content without revealing equal contents.",
+			},
+			"content_hash_key": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Random key of `content_hash` in hex format.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"recipient_fingerprints": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Fingerprints of the recipient keys.",
+			},
+			"ciphertext": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Encrypted message in armored format.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (g EncryptedMessageResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data encryptedMessageModelV1
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.PublicKeys.IsNull() && data.Passphrase.IsNull() {
+		resp.Diagnostics.AddError("Missing recipients", "At least one of public_keys and passphrase must be set.")
+	}
+
+	if !data.S2K.IsNull() && data.Passphrase.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("s2k"), "Invalid S2K settings", "s2k requires passphrase to be set.")
+	}
+
+	symmetric, diags := data.symmetric(ctx)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := symmetric.validate(); err != nil {
+		resp.Diagnostics.AddError("Invalid symmetric encryption settings", err.Error())
+	}
+}
+
+// ModifyPlan computes the content hash and recipient fingerprints and marks the ciphertext as unknown if any of them
+// or the options changed.
+func (g EncryptedMessageResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan encryptedMessageModelV1
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(plan.computeHashes(ctx)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !req.State.Raw.IsNull() {
+		var state encryptedMessageModelV1
+
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if !plan.ContentHash.Equal(state.ContentHash) ||
+			!plan.RecipientFingerprints.Equal(state.RecipientFingerprints) ||
+			!plan.Passphrase.Equal(state.Passphrase) ||
+			!plan.Cipher.Equal(state.Cipher) ||
+			!plan.AEADMode.Equal(state.AEADMode) ||
+			!plan.S2K.Equal(state.S2K) ||
+			!plan.Compress.Equal(state.Compress) ||
+			!plan.HideRecipients.Equal(state.HideRecipients) {
+			plan.Ciphertext = types.StringUnknown()
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+func (g EncryptedMessageResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data encryptedMessageModelV1
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.generateKeys()...)
+	resp.Diagnostics.Append(data.computeHashes(ctx)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(g.encrypt(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (g EncryptedMessageResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Nothing to do here.
+}
+
+// Update re-encrypts the content if the plan marked the ciphertext as unknown, otherwise it copies the plan value to
+// the state.
+func (g EncryptedMessageResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var model encryptedMessageModelV1
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &model)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(model.computeHashes(ctx)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if model.Ciphertext.IsUnknown() {
+		resp.Diagnostics.Append(g.encrypt(ctx, &model)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
+
+func (g EncryptedMessageResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here.
+}
+
+// encrypt encrypts the content of the model to its recipients.
+func (g EncryptedMessageResource) encrypt(ctx context.Context, data *encryptedMessageModelV1) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var defaults GpgProviderModel
+	if g.defaults != nil {
+		defaults = *g.defaults
+	}
+
+	keyProfile, err := profileByName(resolveString(types.StringNull(), defaults.DefaultProfile, defaultProfileName).ValueString())
+	if err != nil {
+		diags.AddError("GPG encryption failed", err.Error())
+		return diags
+	}
+
+	symmetric, symmetricDiags := data.symmetric(ctx)
+	diags.Append(symmetricDiags...)
+
+	if diags.HasError() {
+		return diags
+	}
+	symmetric.apply(keyProfile)
+
+	var publicKeys []string
+	diags.Append(data.PublicKeys.ElementsAs(ctx, &publicKeys, false)...)
+
+	if diags.HasError() {
+		return diags
+	}
+
+	recipients, err := gpgcrypto.NewKeyRing(nil)
+	if err != nil {
+		diags.AddError("GPG encryption failed", fmt.Sprintf("NewKeyRing failed with error: %s", err))
+		return diags
+	}
+	for i, publicKey := range publicKeys {
+		key, err := gpgcrypto.NewKeyFromArmored(publicKey)
+		if err != nil {
+			diags.AddAttributeError(path.Root("public_keys").AtListIndex(i), "GPG encryption failed", fmt.Sprintf("NewKeyFromArmored failed with error: %s", err))
+			return diags
+		}
+		if err := recipients.AddKey(key); err != nil {
+			diags.AddAttributeError(path.Root("public_keys").AtListIndex(i), "GPG encryption failed", fmt.Sprintf("AddKey failed with error: %s", err))
+			return diags
+		}
+	}
+
+	var pgp = gpgcrypto.PGPWithProfile(keyProfile)
+
+	builder := pgp.Encryption()
+	if len(publicKeys) > 0 {
+		if data.HideRecipients.ValueBool() {
+			builder = builder.HiddenRecipients(recipients)
+		} else {
+			builder = builder.Recipients(recipients)
+		}
+	}
+	if !data.Passphrase.IsNull() {
+		builder = builder.Password([]byte(data.Passphrase.ValueString()))
+	}
+	if data.Compress.ValueBool() {
+		builder = builder.Compress()
+	}
+
+	handle, err := builder.New()
+	if err != nil {
+		diags.AddError("GPG encryption failed", fmt.Sprintf("New failed with error: %s", err))
+		return diags
+	}
+
+	message, err := handle.Encrypt([]byte(data.Content.ValueString()))
+	if err != nil {
+		diags.AddError("GPG encryption failed", fmt.Sprintf("Encrypt failed with error: %s", err))
+		return diags
+	}
+
+	ciphertext, err := message.ArmorWithCustomHeaders(resolveString(types.StringNull(), defaults.DefaultComment, "").ValueString(), "")
+	if err != nil {
+		diags.AddError("GPG encryption failed", fmt.Sprintf("Armor failed with error: %s", err))
+		return diags
+	}
+
+	data.Ciphertext = types.StringValue(ciphertext)
+	return diags
+}
+
+type encryptedMessageModelV1 struct {
+	Id                    types.String `tfsdk:"id"`
+	Content               types.String `tfsdk:"content"`
+	PublicKeys            types.List   `tfsdk:"public_keys"`
+	Passphrase            types.String `tfsdk:"passphrase"`
+	Cipher                types.String `tfsdk:"cipher"`
+	AEADMode              types.String `tfsdk:"aead_mode"`
+	S2K                   types.Object `tfsdk:"s2k"`
+	Compress              types.Bool   `tfsdk:"compress"`
+	HideRecipients        types.Bool   `tfsdk:"hide_recipients"`
+	ContentHash           types.String `tfsdk:"content_hash"`
+	ContentHashKey        types.String `tfsdk:"content_hash_key"`
+	RecipientFingerprints types.List   `tfsdk:"recipient_fingerprints"`
+	Ciphertext            types.String `tfsdk:"ciphertext"`
+}
+
+// generateKeys sets the id and the content hash key to random values if they are unknown.
+func (m *encryptedMessageModelV1) generateKeys() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, value := range []*types.String{&m.Id, &m.ContentHashKey} {
+		if !value.IsUnknown() {
+			continue
+		}
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			diags.AddError("GPG encryption failed", fmt.Sprintf("Read failed with error: %s", err))
+			return diags
+		}
+		*value = types.StringValue(hex.EncodeToString(random))
+	}
+	return diags
+}
+
+// computeHashes sets the content hash and recipient fingerprints from the content, the content hash key and the public
+// keys. They are set to unknown if their inputs are unknown.
+func (m *encryptedMessageModelV1) computeHashes(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.Content.IsUnknown() || m.ContentHashKey.IsUnknown() {
+		m.ContentHash = types.StringUnknown()
+	} else {
+		key, err := hex.DecodeString(m.ContentHashKey.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("content_hash_key"), "Invalid content hash key", fmt.Sprintf("DecodeString failed with error: %s", err))
+			return diags
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(m.Content.ValueString()))
+		m.ContentHash = types.StringValue(hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	if m.PublicKeys.IsUnknown() {
+		m.RecipientFingerprints = types.ListUnknown(types.StringType)
+		return diags
+	}
+
+	fingerprints := []string{}
+	for i, element := range m.PublicKeys.Elements() {
+		publicKey, ok := element.(types.String)
+		if !ok || publicKey.IsUnknown() {
+			m.RecipientFingerprints = types.ListUnknown(types.StringType)
+			return diags
+		}
+		key, err := gpgcrypto.NewKeyFromArmored(publicKey.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("public_keys").AtListIndex(i), "Invalid public key", fmt.Sprintf("NewKeyFromArmored failed with error: %s", err))
+			return diags
+		}
+		fingerprints = append(fingerprints, key.GetFingerprint())
+	}
+
+	var listDiags diag.Diagnostics
+	m.RecipientFingerprints, listDiags = types.ListValueFrom(ctx, types.StringType, fingerprints)
+	diags.Append(listDiags...)
+	return diags
+}
+
+// symmetric returns the symmetric encryption settings of the model.
+func (m *encryptedMessageModelV1) symmetric(ctx context.Context) (*symmetricModelV1, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	symmetric := &symmetricModelV1{
+		Cipher:   m.Cipher,
+		AEADMode: m.AEADMode,
+	}
+	if !m.S2K.IsNull() && !m.S2K.IsUnknown() {
+		symmetric.S2K = &s2kModelV1{}
+		diags.Append(m.S2K.As(ctx, symmetric.S2K, basetypes.ObjectAsOptions{})...)
+	}
+	return symmetric, diags
+}
@@ -0,0 +1,228 @@
+package provider
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-testing/compare"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"testing"
+	"unsafe"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccEncryptedMessageResource(t *testing.T) {
+	sameCiphertext := statecheck.CompareValue(compare.ValuesSame())
+	differentCiphertext := statecheck.CompareValue(compare.ValuesDiffer())
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccEncryptedMessageResourceConfig("hello", "gpg_key_pair.test.public_key"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgEncryptedMessage("gpg_encrypted_message.test", "gpg_key_pair.test", "hello"),
+					testAccCheckGpgEncryptedMessageContentHash("gpg_encrypted_message.test", "hello"),
+				),
+				ConfigStateChecks: []statecheck.StateCheck{
+					sameCiphertext.AddStateValue("gpg_encrypted_message.test", tfjsonpath.New("ciphertext")),
+					differentCiphertext.AddStateValue("gpg_encrypted_message.test", tfjsonpath.New("ciphertext")),
+				},
+			},
+			// Update testing, a changed public key with the same fingerprint keeps the ciphertext
+			{
+				Config: testAccEncryptedMessageResourceConfig("hello", "gpg_key_certification.test.certified_public_key"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgEncryptedMessage("gpg_encrypted_message.test", "gpg_key_pair.test", "hello"),
+				),
+				ConfigStateChecks: []statecheck.StateCheck{
+					sameCiphertext.AddStateValue("gpg_encrypted_message.test", tfjsonpath.New("ciphertext")),
+				},
+			},
+			// Update testing, changed content is encrypted again
+			{
+				Config: testAccEncryptedMessageResourceConfig("world", "gpg_key_certification.test.certified_public_key"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgEncryptedMessage("gpg_encrypted_message.test", "gpg_key_pair.test", "world"),
+					testAccCheckGpgEncryptedMessageContentHash("gpg_encrypted_message.test", "world"),
+				),
+				ConfigStateChecks: []statecheck.StateCheck{
+					differentCiphertext.AddStateValue("gpg_encrypted_message.test", tfjsonpath.New("ciphertext")),
+				},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccEncryptedMessageResource_passphrase(t *testing.T) {
+	differentCiphertext := statecheck.CompareValue(compare.ValuesDiffer())
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccEncryptedMessageResourcePassphraseConfig("top secret"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgEncryptedMessagePassphrase("gpg_encrypted_message.test", "hello"),
+					resource.TestCheckResourceAttr("gpg_encrypted_message.test", "recipient_fingerprints.#", "0"),
+				),
+				ConfigStateChecks: []statecheck.StateCheck{
+					differentCiphertext.AddStateValue("gpg_encrypted_message.test", tfjsonpath.New("ciphertext")),
+				},
+			},
+			// Update testing, a changed passphrase encrypts the content again
+			{
+				Config: testAccEncryptedMessageResourcePassphraseConfig("even more secret"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgEncryptedMessagePassphrase("gpg_encrypted_message.test", "hello"),
+				),
+				ConfigStateChecks: []statecheck.StateCheck{
+					differentCiphertext.AddStateValue("gpg_encrypted_message.test", tfjsonpath.New("ciphertext")),
+				},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+// testAccCheckGpgEncryptedMessageContentHash checks that the content hash is keyed with the content hash key and that
+// neither the id nor the content hash is the plain SHA-256 hash of the content.
+func testAccCheckGpgEncryptedMessageContentHash(name string, content string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+
+		key, err := hex.DecodeString(rs.Primary.Attributes["content_hash_key"])
+		if err != nil {
+			return err
+		}
+		if len(key) != 32 {
+			return fmt.Errorf("unexpected content hash key length %d", len(key))
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(content))
+		if contentHash := hex.EncodeToString(mac.Sum(nil)); rs.Primary.Attributes["content_hash"] != contentHash {
+			return fmt.Errorf("expected content hash %s, got %s", contentHash, rs.Primary.Attributes["content_hash"])
+		}
+
+		hash := sha256.Sum256([]byte(content))
+		if rs.Primary.Attributes["id"] == hex.EncodeToString(hash[:]) {
+			return fmt.Errorf("id %s is the SHA-256 hash of the content", rs.Primary.Attributes["id"])
+		}
+		return nil
+	}
+}
+
+func testAccCheckGpgEncryptedMessagePassphrase(name string, content string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+
+		decryption, err := crypto.PGP().Decryption().Password([]byte(rs.Primary.Attributes["passphrase"])).New()
+		if err != nil {
+			return err
+		}
+		result, err := decryption.Decrypt([]byte(rs.Primary.Attributes["ciphertext"]), crypto.Armor)
+		if err != nil {
+			return err
+		}
+		if string(result.Bytes()) != content {
+			return fmt.Errorf("unexpected plaintext %q", result.Bytes())
+		}
+		return nil
+	}
+}
+
+func testAccCheckGpgEncryptedMessage(name string, keyName string, content string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+		keyRs, ok := s.RootModule().Resources[keyName]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", keyName)
+		}
+
+		passphrase := keyRs.Primary.Attributes["passphrase"]
+		privateKey, err := crypto.NewPrivateKeyFromArmored(keyRs.Primary.Attributes["private_key"], unsafe.Slice(unsafe.StringData(passphrase), len(passphrase)))
+		if err != nil {
+			return err
+		}
+
+		decryption, err := crypto.PGP().Decryption().DecryptionKey(privateKey).New()
+		if err != nil {
+			return err
+		}
+		result, err := decryption.Decrypt([]byte(rs.Primary.Attributes["ciphertext"]), crypto.Armor)
+		if err != nil {
+			return err
+		}
+		if string(result.Bytes()) != content {
+			return fmt.Errorf("unexpected plaintext %q", result.Bytes())
+		}
+		return nil
+	}
+}
+
+func testAccEncryptedMessageResourceConfig(content string, publicKey string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "ca" {
+  identities = [{
+	name  = "Example CA"
+	email = "ca@example.com"
+  }]
+  passphrase = "top secret"
+}
+
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+}
+
+resource "gpg_key_certification" "test" {
+  signer_private_key = gpg_key_pair.ca.private_key
+  signer_passphrase  = gpg_key_pair.ca.passphrase
+  public_key         = gpg_key_pair.test.public_key
+}
+
+resource "gpg_encrypted_message" "test" {
+  content     = %[1]q
+  public_keys = [%[2]s]
+  compress    = true
+}
+`, content, publicKey)
+}
+
+func testAccEncryptedMessageResourcePassphraseConfig(passphrase string) string {
+	return fmt.Sprintf(`
+resource "gpg_encrypted_message" "test" {
+  content    = "hello"
+  passphrase = %[1]q
+  cipher     = "aes128"
+  aead_mode  = "ocb"
+  s2k = {
+	mode          = "argon2"
+	argon2_passes = 1
+	argon2_memory = 1024
+  }
+}
+`, passphrase)
+}
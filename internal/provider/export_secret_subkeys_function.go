@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"strings"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ExportSecretSubkeysFunction{}
+
+func NewExportSecretSubkeysFunction() function.Function {
+	return &ExportSecretSubkeysFunction{}
+}
+
+type ExportSecretSubkeysFunction struct{}
+
+func (f ExportSecretSubkeysFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "export_secret_subkeys"
+}
+
+func (f ExportSecretSubkeysFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Strip the secret primary key from a private key",
+		MarkdownDescription: "Returns the private key with the secret key material of the primary key removed using the GNU " +
+			"dummy S2K extension, like `gpg --export-secret-subkeys`. The subkeys keep their secret key material and " +
+			"passphrase, so the result can sign and decrypt with the subkeys but cannot certify keys or add subkeys, " +
+			"which allows keeping the primary key offline.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "private_key",
+				MarkdownDescription: "Private key in armored format.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f ExportSecretSubkeysFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var armoredKey string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &armoredKey))
+
+	if resp.Error != nil {
+		return
+	}
+
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredKey))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("ReadArmoredKeyRing failed with error: %s", err))
+		return
+	}
+	if len(entities) != 1 || entities[0].PrivateKey == nil {
+		resp.Error = function.NewArgumentFuncError(0, "Expected exactly one private key.")
+		return
+	}
+
+	serialized, err := exportSecretSubkeys(entities[0])
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Exporting the secret subkeys failed with error: %s", err))
+		return
+	}
+
+	privateKey, err := armorPrivateKey(entities[0], serialized, "")
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Armor failed with error: %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, privateKey))
+}
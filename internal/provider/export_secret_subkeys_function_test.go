@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"fmt"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccExportSecretSubkeysFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccExportSecretSubkeysFunctionConfig("gpg_key_pair.test.private_key"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckOutputEqualsAttr("subkeys_only", "gpg_key_pair.test", "private_subkeys_only"),
+					testAccCheckGpgSecretSubkeys("gpg_key_pair.test", filepath.Join(t.TempDir(), "gnupg")),
+				),
+			},
+			{
+				Config:      testAccExportSecretSubkeysFunctionConfig("gpg_key_pair.test.public_key"),
+				ExpectError: regexp.MustCompile(`Expected exactly one private key`),
+			},
+		},
+	})
+}
+
+// testAccCheckGpgSecretSubkeys checks that private_subkeys_only of the key pair only lacks the secret key material of
+// the primary key and, if gpg is installed and the key is version 4, that gpg imports it as offline primary key and
+// decrypts with the subkey.
+func testAccCheckGpgSecretSubkeys(name string, home string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+		subkeysOnly := rs.Primary.Attributes["private_subkeys_only"]
+
+		entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(subkeysOnly))
+		if err != nil {
+			return err
+		}
+		if len(entities) != 1 || entities[0].PrivateKey == nil || !entities[0].PrivateKey.Dummy() {
+			return fmt.Errorf("expected a private key with a GNU dummy primary key:\n%s", subkeysOnly)
+		}
+		for _, subkey := range entities[0].Subkeys {
+			if subkey.PrivateKey == nil || subkey.PrivateKey.Dummy() {
+				return fmt.Errorf("subkey %X has no secret key material", subkey.PublicKey.Fingerprint)
+			}
+		}
+		if err := entities[0].DecryptPrivateKeys([]byte(rs.Primary.Attributes["passphrase"])); err != nil {
+			return err
+		}
+
+		if _, err := exec.LookPath("gpg"); err != nil || entities[0].PrimaryKey.Version != 4 {
+			return nil
+		}
+		defer func() {
+			_ = exec.Command("gpgconf", "--homedir", home, "--kill", "gpg-agent").Run()
+		}()
+
+		gpg := func(stdin string, args ...string) (string, error) {
+			cmd := exec.Command("gpg", append([]string{"--homedir", home, "--batch", "--pinentry-mode", "loopback", "--passphrase", rs.Primary.Attributes["passphrase"]}, args...)...)
+			cmd.Stdin = strings.NewReader(stdin)
+			var stderr strings.Builder
+			cmd.Stderr = &stderr
+			out, err := cmd.Output()
+			if err != nil {
+				return "", fmt.Errorf("gpg %s failed with error: %s\n%s", strings.Join(args, " "), err, stderr.String())
+			}
+			return string(out), nil
+		}
+		if err := os.MkdirAll(home, 0o700); err != nil {
+			return err
+		}
+		if _, err := gpg(subkeysOnly, "--import"); err != nil {
+			return err
+		}
+		output, err := gpg("", "--with-colons", "--list-secret-keys")
+		if err != nil {
+			return err
+		}
+		if !regexp.MustCompile(`(?m)^sec(:[^:]*){13}:#:`).MatchString(output) {
+			return fmt.Errorf("gpg does not list the primary key as offline:\n%s", output)
+		}
+
+		ciphertext, err := gpg("content", "--armor", "--trust-model", "always", "--recipient", rs.Primary.Attributes["fingerprint"], "--encrypt", "--output", "-")
+		if err != nil {
+			return err
+		}
+		if _, err := gpg(ciphertext, "--decrypt"); err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
+func testAccExportSecretSubkeysFunctionConfig(key string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+}
+
+output "subkeys_only" {
+  value     = provider::gpg::export_secret_subkeys(%s)
+  sensitive = true
+}
+`, key)
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &FromPaperkeyFunction{}
+
+func NewFromPaperkeyFunction() function.Function {
+	return &FromPaperkeyFunction{}
+}
+
+type FromPaperkeyFunction struct{}
+
+func (f FromPaperkeyFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "from_paperkey"
+}
+
+func (f FromPaperkeyFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Restore a private key from paperkey format and the public key",
+		MarkdownDescription: "Combines the secret parts in [paperkey](https://www.jabberwocky.com/software/paperkey/) " +
+			"text format with the public key and returns the private key in armored format. The checksums of all lines " +
+			"and of the entire data are verified. The private key keeps the passphrase it was protected with.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "paperkey",
+				MarkdownDescription: "Secret parts of the key in paperkey text format.",
+			},
+			function.StringParameter{
+				Name:                "public_key",
+				MarkdownDescription: "Public key in armored format.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f FromPaperkeyFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var paperkey, publicKey string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &paperkey, &publicKey))
+
+	if resp.Error != nil {
+		return
+	}
+
+	key, err := gpgcrypto.NewKeyFromArmored(publicKey)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("NewKeyFromArmored failed with error: %s", err))
+		return
+	}
+
+	publicKeyBytes, err := key.GetPublicKey()
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("GetPublicKey failed with error: %s", err))
+		return
+	}
+
+	secretKey, err := fromPaperkey(paperkey, publicKeyBytes)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Restoring the private key failed with error: %s", err))
+		return
+	}
+
+	privateKey, err := gpgcrypto.NewKey(secretKey)
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("NewKey failed with error: %s", err))
+		return
+	}
+
+	armored, err := privateKey.Armor()
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Armor failed with error: %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, armored))
+}
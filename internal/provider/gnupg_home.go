@@ -0,0 +1,501 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	gnupgPubring        = "pubring.kbx"
+	gnupgTrustdb        = "trustdb.gpg"
+	gnupgConf           = "gpg.conf"
+	gnupgPrivateKeysDir = "private-keys-v1.d"
+)
+
+// ownertrustLevels maps the ownertrust names of GnuPG to the values stored in trustdb.gpg.
+var ownertrustLevels = map[string]byte{
+	"undefined": 2,
+	"never":     3,
+	"marginal":  4,
+	"full":      5,
+	"ultimate":  6,
+}
+
+// gnupgHome holds the files of a GnuPG home directory by their path relative to the directory.
+type gnupgHome map[string][]byte
+
+// buildGnupgHome returns the files of a GnuPG home directory containing the public keys of the entities in
+// pubring.kbx, the unlocked private keys of the entities with private keys in private-keys-v1.d, the ownertrust by
+// fingerprint in trustdb.gpg and gpg.conf unless it is nil. The private keys are protected with the passphrase of their
+// fingerprint and only stored unprotected if it is empty. Salt and nonce of the protection are derived from the
+// fingerprint, so that the files only change with the keys and passphrases. Only version 4 keys are supported, as the
+// keybox format only supports fingerprints of version 4 keys.
+func buildGnupgHome(entities []*openpgp.Entity, passphrases map[string][]byte, ownertrust map[string]byte, conf *string) (gnupgHome, error) {
+	home := gnupgHome{}
+
+	pubring := keyboxHeader()
+	for _, entity := range entities {
+		blob, err := keyboxBlob(entity)
+		if err != nil {
+			return nil, err
+		}
+		pubring = append(pubring, blob...)
+
+		passphrase := passphrases[strings.ToUpper(hex.EncodeToString(entity.PrimaryKey.Fingerprint))]
+		random := agentProtectionRand(entity.PrimaryKey.Fingerprint)
+		for _, key := range secretKeys(entity) {
+			grip, err := keygrip(key.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("key %X: %w", key.PublicKey.KeyId, err)
+			}
+			content, err := agentPrivateKey(key.PrivateKey, passphrase, random)
+			if err != nil {
+				return nil, fmt.Errorf("key %X: %w", key.PublicKey.KeyId, err)
+			}
+			home[filepath.Join(gnupgPrivateKeysDir, strings.ToUpper(hex.EncodeToString(grip))+".key")] = []byte(content)
+		}
+	}
+	home[gnupgPubring] = pubring
+
+	trustdb, err := encodeTrustdb(ownertrust)
+	if err != nil {
+		return nil, err
+	}
+	home[gnupgTrustdb] = trustdb
+
+	if conf != nil {
+		home[gnupgConf] = []byte(*conf)
+	}
+	return home, nil
+}
+
+// checksum returns the SHA-256 checksum of the paths and contents of all files.
+func (h gnupgHome) checksum() string {
+	hash := sha256.New()
+	for _, name := range sortedKeys(h) {
+		fmt.Fprintf(hash, "%s\x00%d\x00", name, len(h[name]))
+		hash.Write(h[name])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// write creates the directory and writes all files with permissions that GnuPG accepts, i.e. 0700 for directories and
+// 0600 for files. Private keys in private-keys-v1.d that are not part of the home are removed.
+func (h gnupgHome) write(dir string) error {
+	for _, d := range []string{dir, filepath.Join(dir, gnupgPrivateKeysDir)} {
+		if err := os.MkdirAll(d, 0o700); err != nil {
+			return err
+		}
+		if err := os.Chmod(d, 0o700); err != nil {
+			return err
+		}
+	}
+
+	stale, err := filepath.Glob(filepath.Join(dir, gnupgPrivateKeysDir, "*.key"))
+	if err != nil {
+		return err
+	}
+	for _, name := range stale {
+		if _, ok := h[filepath.Join(gnupgPrivateKeysDir, filepath.Base(name))]; !ok {
+			if err := os.Remove(name); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(h) {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, h[name], 0o600); err != nil {
+			return err
+		}
+		if err := os.Chmod(path, 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drift compares the directory with the home and returns a description of the first difference, or an empty string.
+// pubring.kbx and trustdb.gpg are compared by their keys and ownertrust, as GnuPG updates their metadata. Private keys
+// in private-keys-v1.d that are not part of the home are differences, as write removes them.
+func (h gnupgHome) drift(dir string) (string, error) {
+	for _, d := range []string{dir, filepath.Join(dir, gnupgPrivateKeysDir)} {
+		info, err := os.Stat(d)
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Sprintf("%s is missing", d), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode().Perm() != 0o700 {
+			return fmt.Sprintf("%s has permissions %04o instead of 0700", d, info.Mode().Perm()), nil
+		}
+	}
+
+	for _, name := range sortedKeys(h) {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Sprintf("%s is missing", path), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode().Perm() != 0o600 {
+			return fmt.Sprintf("%s has permissions %04o instead of 0600", path, info.Mode().Perm()), nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+
+		var equal bool
+		switch name {
+		case gnupgPubring:
+			equal, err = equalKeyboxes(content, h[name])
+		case gnupgTrustdb:
+			equal, err = equalTrustdbs(content, h[name])
+		default:
+			equal = bytes.Equal(content, h[name])
+		}
+		if err != nil {
+			return fmt.Sprintf("%s is invalid: %s", path, err), nil
+		}
+		if !equal {
+			return fmt.Sprintf("%s has been modified", path), nil
+		}
+	}
+
+	keys, err := filepath.Glob(filepath.Join(dir, gnupgPrivateKeysDir, "*.key"))
+	if err != nil {
+		return "", err
+	}
+	for _, name := range keys {
+		if _, ok := h[filepath.Join(gnupgPrivateKeysDir, filepath.Base(name))]; !ok {
+			return fmt.Sprintf("%s is not managed", name), nil
+		}
+	}
+	return "", nil
+}
+
+// keyboxHeader returns the header blob of a keybox file.
+func keyboxHeader() []byte {
+	header := make([]byte, 32)
+	binary.BigEndian.PutUint32(header[0:], 32)
+	header[4] = 1 // blob type header
+	header[5] = 1 // version
+	binary.BigEndian.PutUint16(header[6:], 2)
+	copy(header[8:], "KBXf")
+	return header
+}
+
+// keyboxBlob returns the OpenPGP blob of the public keys of the entity for a keybox file.
+func keyboxBlob(entity *openpgp.Entity) ([]byte, error) {
+	if entity.PrimaryKey.Version != 4 {
+		return nil, fmt.Errorf("key %X: only version 4 keys are supported", entity.PrimaryKey.Fingerprint)
+	}
+
+	var keyblock bytes.Buffer
+	if err := entity.Serialize(&keyblock); err != nil {
+		return nil, err
+	}
+	packets, err := packetBodies(keyblock.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprints := [][]byte{entity.PrimaryKey.Fingerprint}
+	for _, subkey := range entity.Subkeys {
+		fingerprints = append(fingerprints, subkey.PublicKey.Fingerprint)
+	}
+	var userIds [][2]int
+	signatures := 0
+	for _, p := range packets {
+		switch p.tag {
+		case 13:
+			userIds = append(userIds, [2]int{p.offset, p.length})
+		case 2:
+			signatures++
+		}
+	}
+
+	var blob bytes.Buffer
+	u16 := func(v int) { _ = binary.Write(&blob, binary.BigEndian, uint16(v)) }
+	u32 := func(v int) { _ = binary.Write(&blob, binary.BigEndian, uint32(v)) }
+
+	// The fixed part of the blob precedes the key block, whose offset is needed for the user IDs.
+	dataOffset := 20 + 28*len(fingerprints) + 2 + 4 + 12*len(userIds) + 4 + 4*signatures + 4 + 4 + 4 + 4 + 4
+
+	u32(0)            // length, set below
+	blob.WriteByte(2) // blob type OpenPGP
+	blob.WriteByte(1) // version
+	u16(0)            // blob flags
+	u32(dataOffset)
+	u32(keyblock.Len())
+	u16(len(fingerprints))
+	u16(28)
+	for _, fingerprint := range fingerprints {
+		keyInfo := blob.Len()
+		blob.Write(fingerprint)
+		u32(keyInfo + 12) // offset of the key ID, the last 8 bytes of the fingerprint
+		u16(0)            // key flags
+		u16(0)            // reserved
+	}
+	u16(0) // serial number length
+	u16(len(userIds))
+	u16(12)
+	for _, userId := range userIds {
+		u32(dataOffset + userId[0])
+		u32(userId[1])
+		u16(0)            // user ID flags
+		blob.WriteByte(0) // validity
+		blob.WriteByte(0) // reserved
+	}
+	u16(signatures)
+	u16(4)
+	for i := 0; i < signatures; i++ {
+		u32(0) // signature expiration, not checked
+	}
+	blob.WriteByte(0) // ownertrust
+	blob.WriteByte(0) // all validity
+	u16(0)            // reserved
+	u32(0)            // recheck after
+	u32(0)            // latest timestamp
+	u32(int(entity.PrimaryKey.CreationTime.Unix()))
+	u32(0) // size of reserved space
+
+	if blob.Len() != dataOffset {
+		return nil, fmt.Errorf("unexpected keybox blob header length %d", blob.Len())
+	}
+	blob.Write(keyblock.Bytes())
+
+	result := blob.Bytes()
+	binary.BigEndian.PutUint32(result, uint32(len(result)+sha1.Size))
+	checksum := sha1.Sum(result)
+	return append(result, checksum[:]...), nil
+}
+
+// keyboxKeyblocks returns the key blocks of all OpenPGP blobs of a keybox file.
+func keyboxKeyblocks(data []byte) ([][]byte, error) {
+	var keyblocks [][]byte
+	for len(data) > 0 {
+		if len(data) < 5 {
+			return nil, errors.New("truncated blob")
+		}
+		length := int(binary.BigEndian.Uint32(data))
+		if length < 5 || length > len(data) {
+			return nil, fmt.Errorf("invalid blob length %d", length)
+		}
+		blob := data[:length]
+		data = data[length:]
+		if blob[4] != 2 {
+			continue
+		}
+		if length < 16 {
+			return nil, errors.New("truncated OpenPGP blob")
+		}
+		offset := int(binary.BigEndian.Uint32(blob[8:]))
+		size := int(binary.BigEndian.Uint32(blob[12:]))
+		if offset+size > length {
+			return nil, errors.New("invalid key block offset")
+		}
+		keyblocks = append(keyblocks, blob[offset:offset+size])
+	}
+	return keyblocks, nil
+}
+
+// equalKeyboxes returns whether two keybox files contain the same key blocks.
+func equalKeyboxes(a, b []byte) (bool, error) {
+	keyblocksA, err := keyboxKeyblocks(a)
+	if err != nil {
+		return false, err
+	}
+	keyblocksB, err := keyboxKeyblocks(b)
+	if err != nil {
+		return false, err
+	}
+	if len(keyblocksA) != len(keyblocksB) {
+		return false, nil
+	}
+	for i := range keyblocksA {
+		if !bytes.Equal(keyblocksA[i], keyblocksB[i]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// packetBody describes the position of an OpenPGP packet body in a serialized key block.
+type packetBody struct {
+	tag    byte
+	offset int
+	length int
+}
+
+// packetBodies returns the positions of the bodies of all packets in a serialized key block. Partial body lengths are
+// not supported, as they are not used in key blocks.
+func packetBodies(data []byte) ([]packetBody, error) {
+	var bodies []packetBody
+	for offset := 0; offset < len(data); {
+		header := data[offset]
+		if header&0x80 == 0 {
+			return nil, fmt.Errorf("invalid packet header at offset %d", offset)
+		}
+		var tag byte
+		var length, headerLength int
+		rest := data[offset+1:]
+		if header&0x40 != 0 {
+			tag = header & 0x3f
+			switch {
+			case len(rest) >= 1 && rest[0] < 192:
+				length, headerLength = int(rest[0]), 2
+			case len(rest) >= 2 && rest[0] < 224:
+				length, headerLength = (int(rest[0])-192)<<8+int(rest[1])+192, 3
+			case len(rest) >= 5 && rest[0] == 255:
+				length, headerLength = int(binary.BigEndian.Uint32(rest[1:])), 6
+			default:
+				return nil, fmt.Errorf("unsupported packet length at offset %d", offset)
+			}
+		} else {
+			tag = (header >> 2) & 0x0f
+			switch {
+			case header&3 == 0 && len(rest) >= 1:
+				length, headerLength = int(rest[0]), 2
+			case header&3 == 1 && len(rest) >= 2:
+				length, headerLength = int(binary.BigEndian.Uint16(rest)), 3
+			case header&3 == 2 && len(rest) >= 4:
+				length, headerLength = int(binary.BigEndian.Uint32(rest)), 5
+			default:
+				return nil, fmt.Errorf("unsupported packet length at offset %d", offset)
+			}
+		}
+		if offset+headerLength+length > len(data) {
+			return nil, fmt.Errorf("truncated packet at offset %d", offset)
+		}
+		bodies = append(bodies, packetBody{tag: tag, offset: offset + headerLength, length: length})
+		offset += headerLength + length
+	}
+	return bodies, nil
+}
+
+const (
+	trustdbRecordLength = 40
+	// trustdbTableRecords is the number of records of a hash table with 256 items of 9 items per record.
+	trustdbTableRecords = 29
+	trustdbTableItems   = 9
+
+	trustdbVersion   = 1
+	trustdbHashTable = 10
+	trustdbTrust     = 12
+)
+
+// encodeTrustdb returns a trustdb.gpg that only contains the ownertrust by fingerprint. Its next check is due, so
+// that GnuPG computes the validity of the keys on first use.
+func encodeTrustdb(ownertrust map[string]byte) ([]byte, error) {
+	records := [][trustdbRecordLength]byte{{}}
+	version := &records[0]
+	version[0] = trustdbVersion
+	copy(version[1:], "gpg")
+	version[4] = 3 // version
+	version[5] = 3 // marginals needed
+	version[6] = 1 // completes needed
+	version[7] = 5 // max cert depth
+	version[8] = 1 // trust model pgp
+	version[9] = 2 // min cert level
+	binary.BigEndian.PutUint32(version[16:], 1)
+	binary.BigEndian.PutUint32(version[36:], 1)
+
+	newTable := func() uint32 {
+		start := uint32(len(records))
+		for i := 0; i < trustdbTableRecords; i++ {
+			records = append(records, [trustdbRecordLength]byte{trustdbHashTable})
+		}
+		return start
+	}
+	item := func(table uint32, key byte) []byte {
+		record := table + uint32(key)/trustdbTableItems
+		offset := 2 + 4*(int(key)%trustdbTableItems)
+		return records[record][offset : offset+4]
+	}
+	// insert adds a trust record to the hash table of a level, which is indexed by the fingerprint byte of the level.
+	// Colliding fingerprints are moved to a hash table of the next level.
+	var insert func(table uint32, level int, fingerprint []byte, trust uint32)
+	insert = func(table uint32, level int, fingerprint []byte, trust uint32) {
+		existing := binary.BigEndian.Uint32(item(table, fingerprint[level]))
+		switch {
+		case existing == 0:
+			binary.BigEndian.PutUint32(item(table, fingerprint[level]), trust)
+		case records[existing][0] == trustdbHashTable:
+			insert(existing, level+1, fingerprint, trust)
+		default:
+			next := newTable()
+			insert(next, level+1, records[existing][2:22], existing)
+			insert(next, level+1, fingerprint, trust)
+			binary.BigEndian.PutUint32(item(table, fingerprint[level]), next)
+		}
+	}
+
+	root := newTable()
+	for _, name := range sortedKeys(ownertrust) {
+		fingerprint, err := hex.DecodeString(name)
+		if err != nil || len(fingerprint) != 20 {
+			return nil, fmt.Errorf("invalid fingerprint %q of a version 4 key", name)
+		}
+		trust := [trustdbRecordLength]byte{trustdbTrust}
+		copy(trust[2:], fingerprint)
+		trust[22] = ownertrust[name]
+		records = append(records, trust)
+		insert(root, 0, fingerprint, uint32(len(records)-1))
+	}
+
+	var data bytes.Buffer
+	for _, record := range records {
+		data.Write(record[:])
+	}
+	return data.Bytes(), nil
+}
+
+// decodeOwnertrust returns the ownertrust by fingerprint of all trust records of a trustdb.gpg with an ownertrust.
+func decodeOwnertrust(data []byte) (map[string]byte, error) {
+	if len(data)%trustdbRecordLength != 0 || len(data) == 0 || data[0] != trustdbVersion {
+		return nil, errors.New("not a trust database")
+	}
+	ownertrust := map[string]byte{}
+	for offset := 0; offset < len(data); offset += trustdbRecordLength {
+		record := data[offset : offset+trustdbRecordLength]
+		if record[0] == trustdbTrust && record[22] != 0 {
+			ownertrust[strings.ToUpper(hex.EncodeToString(record[2:22]))] = record[22]
+		}
+	}
+	return ownertrust, nil
+}
+
+// equalTrustdbs returns whether two trust databases contain the same ownertrust.
+func equalTrustdbs(a, b []byte) (bool, error) {
+	ownertrustA, err := decodeOwnertrust(a)
+	if err != nil {
+		return false, err
+	}
+	ownertrustB, err := decodeOwnertrust(b)
+	if err != nil {
+		return false, err
+	}
+	if len(ownertrustA) != len(ownertrustB) {
+		return false, nil
+	}
+	for fingerprint, trust := range ownertrustA {
+		if ownertrustB[fingerprint] != trust {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"encoding/hex"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestBuildGnupgHomeProtectionSalt(t *testing.T) {
+	entity, err := openpgp.NewEntity("John Doe", "", "john.doe@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	grip, err := keygrip(entity.PrimaryKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := strings.ToUpper(hex.EncodeToString(entity.PrimaryKey.Fingerprint))
+	file := filepath.Join(gnupgPrivateKeysDir, strings.ToUpper(hex.EncodeToString(grip))+".key")
+
+	// The extended key format breaks lines inside hex strings, which are joined again for matching.
+	protection := regexp.MustCompile(`\(sha1 #([0-9A-F]{16})# "\d+"\)#([0-9A-F]{24})#\)#([0-9A-F]+)#`)
+	protect := func(passphrase string) []string {
+		home, err := buildGnupgHome([]*openpgp.Entity{entity}, map[string][]byte{fingerprint: []byte(passphrase)}, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		matches := protection.FindStringSubmatch(strings.ReplaceAll(string(home[file]), "\n ", ""))
+		if matches == nil {
+			t.Fatalf("unexpected key file %s", home[file])
+		}
+		return matches[1:]
+	}
+
+	first := protect("top secret")
+	if again := protect("top secret"); strings.Join(again, " ") != strings.Join(first, " ") {
+		t.Errorf("expected the same passphrase to yield the same key file")
+	}
+	other := protect("other secret")
+	if other[0] != first[0] || other[1] != first[1] {
+		t.Errorf("expected salt %s and nonce %s not to depend on the passphrase, got %s and %s", first[0], first[1], other[0], other[1])
+	}
+	if other[2] == first[2] {
+		t.Errorf("expected the ciphertext to depend on the passphrase")
+	}
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// errKeyNotFound is returned by hkpLookup when the keyserver does not know the requested key.
+var errKeyNotFound = errors.New("key not found on keyserver")
+
+// hkpClient is used for all keyserver requests.
+var hkpClient = &http.Client{Timeout: 30 * time.Second}
+
+// hkpBaseURL converts a keyserver address into an HTTP(S) base URL. The HKP schemes `hkp` and `hkps` are mapped to
+// `http` and `https` respectively, `hkp` defaults to the well-known port 11371.
+func hkpBaseURL(keyserver string) (*url.URL, error) {
+	u, err := url.Parse(keyserver)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https":
+	case "hkp":
+		u.Scheme = "http"
+		if u.Port() == "" {
+			u.Host = u.Host + ":11371"
+		}
+	case "hkps":
+		u.Scheme = "https"
+	default:
+		return nil, fmt.Errorf("unsupported keyserver scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("keyserver URL %q has no host", keyserver)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u, nil
+}
+
+// hkpAdd uploads an armored public key to the keyserver using the HKP `/pks/add` endpoint.
+func hkpAdd(ctx context.Context, keyserver string, armoredKey string) error {
+	base, err := hkpBaseURL(keyserver)
+	if err != nil {
+		return err
+	}
+	form := url.Values{"keytext": {armoredKey}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base.String()+"/pks/add", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := hkpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("keyserver responded with %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// hkpLookup fetches the armored public key with the given fingerprint using the HKP `/pks/lookup` endpoint.
+func hkpLookup(ctx context.Context, keyserver string, fingerprint string) (string, error) {
+	base, err := hkpBaseURL(keyserver)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{
+		"op":      {"get"},
+		"options": {"mr"},
+		"search":  {"0x" + strings.ToUpper(fingerprint)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base.String()+"/pks/lookup?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := hkpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return "", errKeyNotFound
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return "", fmt.Errorf("keyserver responded with %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
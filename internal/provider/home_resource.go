@@ -0,0 +1,366 @@
+package provider
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"os"
+	"strings"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &HomeResource{}
+var _ resource.ResourceWithValidateConfig = &HomeResource{}
+var _ resource.ResourceWithModifyPlan = &HomeResource{}
+
+func NewHomeResource() resource.Resource {
+	return &HomeResource{}
+}
+
+type HomeResource struct{}
+
+func (g HomeResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_home"
+}
+
+func (g HomeResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A resource for writing a GnuPG home directory with managed keys to a local path, e.g. for CI runners. " +
+			"The directory contains `pubring.kbx`, the private keys in `private-keys-v1.d/<keygrip>.key` in the extended key format of gpg-agent, " +
+			"the ownertrust in `trustdb.gpg` and an optional `gpg.conf`. Directories are created with permissions `0700` and files with `0600`. " +
+			"Only version 4 keys are supported, as GnuPG 2.2 and later do not support other versions in the keybox format.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Path of the directory.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"path": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Path of the directory, which must not exist or be empty. The directory is removed with all its content on destroy.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"public_keys": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Public keys in armored format to add to `pubring.kbx`. Each element may contain several keys.",
+			},
+			"private_keys": schema.ListNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Private keys to add. Their public keys are added to `pubring.kbx` and their secret keys are stored in `private-keys-v1.d`, which is managed exclusively by this resource, protected with their passphrase like gpg-agent does.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"private_key": schema.StringAttribute{
+							Required:            true,
+							Sensitive:           true,
+							MarkdownDescription: "Private key in armored format.",
+						},
+						"passphrase": schema.StringAttribute{
+							Optional:            true,
+							Sensitive:           true,
+							MarkdownDescription: "Passphrase for unlocking the private key, which also protects the key in `private-keys-v1.d`. Defaults to an unprotected key, which is also stored unprotected.",
+						},
+					},
+				},
+			},
+			"ownertrust": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: fmt.Sprintf("Ownertrust by fingerprint, one of %q. GnuPG computes the validity of the keys on first use.", ownertrustLevelNames()),
+			},
+			"gpg_conf": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Content of `gpg.conf`. Defaults to no `gpg.conf`.",
+			},
+			"fingerprints": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Fingerprints of the keys in `pubring.kbx`.",
+			},
+			"in_sync": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the files in the directory match the configuration. This is `false` if a managed file was modified, removed or has other permissions or if `private-keys-v1.d` contains other keys, which rewrites the files and removes the other keys on the next apply.",
+			},
+		},
+	}
+}
+
+func (g HomeResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data homeModelV1
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Ownertrust.IsNull() || data.Ownertrust.IsUnknown() {
+		return
+	}
+	for fingerprint, level := range data.Ownertrust.Elements() {
+		if decoded, err := hex.DecodeString(fingerprint); err != nil || len(decoded) != 20 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("ownertrust").AtMapKey(fingerprint),
+				"Invalid fingerprint",
+				fmt.Sprintf("%q is not the fingerprint of a version 4 key.", fingerprint),
+			)
+		}
+		level, ok := level.(types.String)
+		if !ok || level.IsNull() || level.IsUnknown() {
+			continue
+		}
+		if _, ok := ownertrustLevels[level.ValueString()]; !ok {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("ownertrust").AtMapKey(fingerprint),
+				"Invalid ownertrust",
+				fmt.Sprintf("Unknown ownertrust %q, expected one of %q.", level.ValueString(), ownertrustLevelNames()),
+			)
+		}
+	}
+}
+
+// ModifyPlan plans to rewrite the files if Read detected drift.
+func (g HomeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("in_sync"), types.BoolValue(true))...)
+}
+
+func (g HomeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data homeModelV1
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	entries, err := os.ReadDir(data.Path.ValueString())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "GPG home creation failed", fmt.Sprintf("ReadDir failed with error: %s", err))
+		return
+	}
+	if len(entries) > 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "GPG home creation failed", fmt.Sprintf("The directory %s is not empty.", data.Path.ValueString()))
+		return
+	}
+
+	resp.Diagnostics.Append(data.write(ctx)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read compares the directory with the configuration. The resource is removed if the directory is missing.
+func (g HomeResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data homeModelV1
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if _, err := os.Stat(data.Path.ValueString()); errors.Is(err, os.ErrNotExist) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	home, _, diags := data.build(ctx)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	drift, err := home.drift(data.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("GPG home comparison failed", fmt.Sprintf("Comparing %s failed with error: %s", data.Path.ValueString(), err))
+		return
+	}
+
+	data.InSync = types.BoolValue(drift == "")
+	if drift != "" {
+		resp.Diagnostics.AddWarning("GPG home diverges", fmt.Sprintf("%s. The files are rewritten on the next apply.", drift))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update rewrites the files of the directory.
+func (g HomeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data homeModelV1
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.write(ctx)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete removes the directory with all its content, including files created by GnuPG.
+func (g HomeResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data homeModelV1
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := os.RemoveAll(data.Path.ValueString()); err != nil {
+		resp.Diagnostics.AddError("GPG home deletion failed", fmt.Sprintf("RemoveAll failed with error: %s", err))
+	}
+}
+
+type homePrivateKeyModelV1 struct {
+	PrivateKey types.String `tfsdk:"private_key"`
+	Passphrase types.String `tfsdk:"passphrase"`
+}
+
+type homeModelV1 struct {
+	Id           types.String            `tfsdk:"id"`
+	Path         types.String            `tfsdk:"path"`
+	PublicKeys   []types.String          `tfsdk:"public_keys"`
+	PrivateKeys  []homePrivateKeyModelV1 `tfsdk:"private_keys"`
+	Ownertrust   types.Map               `tfsdk:"ownertrust"`
+	GpgConf      types.String            `tfsdk:"gpg_conf"`
+	Fingerprints types.List              `tfsdk:"fingerprints"`
+	InSync       types.Bool              `tfsdk:"in_sync"`
+}
+
+// build returns the files of the directory and the fingerprints of the keys. Keys that are part of several elements
+// are only added once, private keys take precedence over public keys.
+func (m *homeModelV1) build(ctx context.Context) (gnupgHome, []string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var entities []*openpgp.Entity
+	passphrases := map[string][]byte{}
+	index := map[string]int{}
+	add := func(entity *openpgp.Entity) {
+		fingerprint := strings.ToUpper(hex.EncodeToString(entity.PrimaryKey.Fingerprint))
+		if i, ok := index[fingerprint]; ok {
+			if entity.PrivateKey != nil {
+				entities[i] = entity
+			}
+			return
+		}
+		index[fingerprint] = len(entities)
+		entities = append(entities, entity)
+	}
+
+	for i, publicKey := range m.PublicKeys {
+		keys, err := openpgp.ReadArmoredKeyRing(strings.NewReader(publicKey.ValueString()))
+		if err != nil {
+			diags.AddAttributeError(path.Root("public_keys").AtListIndex(i), "GPG home creation failed", fmt.Sprintf("ReadArmoredKeyRing failed with error: %s", err))
+			return nil, nil, diags
+		}
+		for _, key := range keys {
+			if key.PrivateKey != nil {
+				diags.AddAttributeError(path.Root("public_keys").AtListIndex(i), "GPG home creation failed", "The key contains private key material, add it to private_keys instead.")
+				return nil, nil, diags
+			}
+			add(key)
+		}
+	}
+
+	for i, privateKey := range m.PrivateKeys {
+		keys, err := openpgp.ReadArmoredKeyRing(strings.NewReader(privateKey.PrivateKey.ValueString()))
+		if err != nil {
+			diags.AddAttributeError(path.Root("private_keys").AtListIndex(i).AtName("private_key"), "GPG home creation failed", fmt.Sprintf("ReadArmoredKeyRing failed with error: %s", err))
+			return nil, nil, diags
+		}
+		if len(keys) != 1 || keys[0].PrivateKey == nil {
+			diags.AddAttributeError(path.Root("private_keys").AtListIndex(i).AtName("private_key"), "GPG home creation failed", "Expected exactly one private key.")
+			return nil, nil, diags
+		}
+		if !privateKey.Passphrase.IsNull() {
+			passphrase := []byte(privateKey.Passphrase.ValueString())
+			if err := keys[0].DecryptPrivateKeys(passphrase); err != nil {
+				diags.AddAttributeError(path.Root("private_keys").AtListIndex(i).AtName("passphrase"), "GPG home creation failed", fmt.Sprintf("DecryptPrivateKeys failed with error: %s", err))
+				return nil, nil, diags
+			}
+			passphrases[strings.ToUpper(hex.EncodeToString(keys[0].PrimaryKey.Fingerprint))] = passphrase
+		}
+		add(keys[0])
+	}
+
+	ownertrust := map[string]byte{}
+	if !m.Ownertrust.IsNull() {
+		var levels map[string]string
+		diags.Append(m.Ownertrust.ElementsAs(ctx, &levels, false)...)
+		if diags.HasError() {
+			return nil, nil, diags
+		}
+		for fingerprint, level := range levels {
+			ownertrust[strings.ToUpper(fingerprint)] = ownertrustLevels[level]
+		}
+	}
+
+	home, err := buildGnupgHome(entities, passphrases, ownertrust, m.GpgConf.ValueStringPointer())
+	if err != nil {
+		diags.AddError("GPG home creation failed", fmt.Sprintf("Building the home directory failed with error: %s", err))
+		return nil, nil, diags
+	}
+
+	fingerprints := make([]string, 0, len(entities))
+	for _, entity := range entities {
+		fingerprints = append(fingerprints, hex.EncodeToString(entity.PrimaryKey.Fingerprint))
+	}
+	return home, fingerprints, diags
+}
+
+// write writes the files of the directory and sets the computed attributes.
+func (m *homeModelV1) write(ctx context.Context) diag.Diagnostics {
+	home, fingerprints, diags := m.build(ctx)
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := home.write(m.Path.ValueString()); err != nil {
+		diags.AddError("GPG home creation failed", fmt.Sprintf("Writing %s failed with error: %s", m.Path.ValueString(), err))
+		return diags
+	}
+
+	fingerprintList, d := types.ListValueFrom(ctx, types.StringType, fingerprints)
+	diags.Append(d...)
+
+	m.Id = m.Path
+	m.Fingerprints = fingerprintList
+	m.InSync = types.BoolValue(true)
+	return diags
+}
+
+// ownertrustLevelNames returns the names of the ownertrust levels in ascending order of trust.
+func ownertrustLevelNames() []string {
+	return []string{"undefined", "never", "marginal", "full", "ultimate"}
+}
@@ -0,0 +1,218 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccHomeResource(t *testing.T) {
+	home := filepath.Join(t.TempDir(), "gnupg")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(s *terraform.State) error {
+			if _, err := os.Stat(home); !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("%s has not been removed", home)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccHomeResourceConfig(home, "ultimate"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_home.test", "id", home),
+					resource.TestCheckResourceAttr("gpg_home.test", "fingerprints.#", "2"),
+					resource.TestCheckResourceAttrPair("gpg_home.test", "fingerprints.0", "gpg_key_pair.engineer", "fingerprint"),
+					resource.TestCheckResourceAttrPair("gpg_home.test", "fingerprints.1", "gpg_key_pair.ca", "fingerprint"),
+					resource.TestCheckResourceAttr("gpg_home.test", "in_sync", "true"),
+					testAccCheckGpgHome(home, "gpg_key_pair.ca", "6"),
+				),
+			},
+			// Drift is detected and the files are rewritten
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(filepath.Join(home, gnupgConf), []byte("armor\n"), 0o644); err != nil {
+						t.Fatal(err)
+					}
+					keys, err := filepath.Glob(filepath.Join(home, gnupgPrivateKeysDir, "*.key"))
+					if err != nil || len(keys) == 0 {
+						t.Fatalf("no private keys found: %v", err)
+					}
+					if err := os.Remove(keys[0]); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testAccHomeResourceConfig(home, "ultimate"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_home.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_home.test", "in_sync", "true"),
+					testAccCheckGpgHome(home, "gpg_key_pair.ca", "6"),
+				),
+			},
+			// Other private keys are detected and removed
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(filepath.Join(home, gnupgPrivateKeysDir, "0123456789ABCDEF0123456789ABCDEF01234567.key"), []byte("(private-key)\n"), 0o600); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testAccHomeResourceConfig(home, "ultimate"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_home.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_home.test", "in_sync", "true"),
+					testAccCheckGpgHome(home, "gpg_key_pair.ca", "6"),
+				),
+			},
+			// Update testing
+			{
+				Config: testAccHomeResourceConfig(home, "full"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_home.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: testAccCheckGpgHome(home, "gpg_key_pair.ca", "5"),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccHomeResourceConfig(home string, ownertrust string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "ca" {
+  identities = [{
+	name  = "Example CA"
+	email = "ca@example.com"
+  }]
+  passphrase = "top secret"
+}
+
+resource "gpg_key_pair" "engineer" {
+  identities = [{
+	name  = "Jane Doe"
+	email = "jane.doe@example.com"
+  }]
+  passphrase = ""
+}
+
+resource "gpg_home" "test" {
+  path        = %[1]q
+  public_keys = [gpg_key_pair.engineer.public_key, gpg_key_pair.ca.public_key]
+  private_keys = [{
+	private_key = gpg_key_pair.ca.private_key
+	passphrase  = gpg_key_pair.ca.passphrase
+  }]
+  ownertrust = {
+	(gpg_key_pair.ca.fingerprint) = %[2]q
+  }
+  gpg_conf = "no-greeting\n"
+}
+`, home, ownertrust)
+}
+
+// testAccCheckGpgHome checks the permissions of the home directory, that the private keys of the key pair are
+// protected and, if gpg is installed, that gpg lists the private key of the key pair with the expected ownertrust value. Ultimately trusted keys must also be valid.
+func testAccCheckGpgHome(home string, keyName string, ownertrust string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		keyRs, ok := s.RootModule().Resources[keyName]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", keyName)
+		}
+		fingerprint := strings.ToUpper(keyRs.Primary.Attributes["fingerprint"])
+
+		for _, d := range []string{home, filepath.Join(home, gnupgPrivateKeysDir)} {
+			info, err := os.Stat(d)
+			if err != nil {
+				return err
+			}
+			if info.Mode().Perm() != 0o700 {
+				return fmt.Errorf("%s has permissions %04o", d, info.Mode().Perm())
+			}
+		}
+		files, err := filepath.Glob(filepath.Join(home, gnupgPrivateKeysDir, "*.key"))
+		if err != nil {
+			return err
+		}
+		if len(files) != 2 {
+			return fmt.Errorf("expected 2 private keys, got %d", len(files))
+		}
+		for i := range files {
+			if expected := keyRs.Primary.Attributes[fmt.Sprintf("keygrips.%d", i)] + ".key"; !slices.Contains(files, filepath.Join(home, gnupgPrivateKeysDir, expected)) {
+				return fmt.Errorf("private key %s is missing", expected)
+			}
+			// The key pair has a passphrase, so its keys must be protected with it.
+			content, err := os.ReadFile(files[i])
+			if err != nil {
+				return err
+			}
+			if !strings.Contains(string(content), "Key: (protected-private-key") {
+				return fmt.Errorf("private key %s is not protected", files[i])
+			}
+		}
+		for _, name := range append(files, filepath.Join(home, gnupgPubring), filepath.Join(home, gnupgTrustdb), filepath.Join(home, gnupgConf)) {
+			info, err := os.Stat(name)
+			if err != nil {
+				return err
+			}
+			if info.Mode().Perm() != 0o600 {
+				return fmt.Errorf("%s has permissions %04o", name, info.Mode().Perm())
+			}
+		}
+
+		if _, err := exec.LookPath("gpg"); err != nil {
+			return nil
+		}
+		defer func() {
+			_ = exec.Command("gpgconf", "--homedir", home, "--kill", "gpg-agent").Run()
+		}()
+
+		output, err := exec.Command("gpg", "--homedir", home, "--batch", "--with-colons", "--list-secret-keys", fingerprint).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("gpg --list-secret-keys failed with error: %s\n%s", err, output)
+		}
+		if !strings.Contains(string(output), "\nfpr:::::::::"+fingerprint+":") {
+			return fmt.Errorf("gpg does not list the private key %s:\n%s", fingerprint, output)
+		}
+
+		output, err = exec.Command("gpg", "--homedir", home, "--batch", "--export-ownertrust").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("gpg --export-ownertrust failed with error: %s\n%s", err, output)
+		}
+		if !strings.Contains(string(output), fmt.Sprintf("%s:%s:", fingerprint, ownertrust)) {
+			return fmt.Errorf("unexpected ownertrust of %s:\n%s", fingerprint, output)
+		}
+
+		if ownertrust != "6" {
+			return nil
+		}
+		output, err = exec.Command("gpg", "--homedir", home, "--batch", "--with-colons", "--list-keys", fingerprint).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("gpg --list-keys failed with error: %s\n%s", err, output)
+		}
+		if !strings.Contains(string(output), "\nuid:u:") {
+			return fmt.Errorf("unexpected validity of %s:\n%s", fingerprint, output)
+		}
+		return nil
+	}
+}
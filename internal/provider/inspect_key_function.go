@@ -0,0 +1,212 @@
+package provider
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"sort"
+	"time"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &InspectKeyFunction{}
+
+func NewInspectKeyFunction() function.Function {
+	return &InspectKeyFunction{}
+}
+
+type InspectKeyFunction struct{}
+
+var inspectedNotationAttrTypes = map[string]attr.Type{
+	"name":           types.StringType,
+	"value":          types.StringType,
+	"human_readable": types.BoolType,
+	"critical":       types.BoolType,
+}
+
+var inspectedSignatureAttrTypes = map[string]attr.Type{
+	"preferred_keyserver": types.StringType,
+	"policy_uri":          types.StringType,
+	"notations":           types.ListType{ElemType: types.ObjectType{AttrTypes: inspectedNotationAttrTypes}},
+}
+
+func (f InspectKeyFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "inspect_key"
+}
+
+func (f InspectKeyFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	identityAttrTypes := map[string]attr.Type{
+		"user_id": types.StringType,
+		"name":    types.StringType,
+		"email":   types.StringType,
+	}
+	for name, attrType := range inspectedSignatureAttrTypes {
+		identityAttrTypes[name] = attrType
+	}
+	keyAttrTypes := map[string]attr.Type{
+		"key_id":      types.StringType,
+		"fingerprint": types.StringType,
+		"version":     types.Int64Type,
+		"created_at":  types.StringType,
+		"identities":  types.ListType{ElemType: types.ObjectType{AttrTypes: identityAttrTypes}},
+	}
+	for name, attrType := range inspectedSignatureAttrTypes {
+		keyAttrTypes[name] = attrType
+	}
+
+	resp.Definition = function.Definition{
+		Summary: "Inspect the self-signatures of a GPG key",
+		MarkdownDescription: "Returns the key ID, fingerprint, version and creation time of a key, and the preferred keyserver, " +
+			"policy URI and notation data of the self-signature carrying the key properties and of the latest self-signature of " +
+			"each identity, sorted by user ID. The values of notations that are not human-readable are returned in hex format.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "key",
+				MarkdownDescription: "Public or private key in armored format.",
+			},
+		},
+		Return: function.ObjectReturn{AttributeTypes: keyAttrTypes},
+	}
+}
+
+func (f InspectKeyFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var armoredKey string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &armoredKey))
+
+	if resp.Error != nil {
+		return
+	}
+
+	key, err := gpgcrypto.NewKeyFromArmored(armoredKey)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("NewKeyFromArmored failed with error: %s", err))
+		return
+	}
+
+	inspection, err := inspectKey(key.GetEntity())
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Inspecting the key failed with error: %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, inspection))
+}
+
+type inspectedNotationModelV1 struct {
+	Name          types.String `tfsdk:"name"`
+	Value         types.String `tfsdk:"value"`
+	HumanReadable types.Bool   `tfsdk:"human_readable"`
+	Critical      types.Bool   `tfsdk:"critical"`
+}
+
+type inspectedIdentityModelV1 struct {
+	UserId             types.String               `tfsdk:"user_id"`
+	Name               types.String               `tfsdk:"name"`
+	Email              types.String               `tfsdk:"email"`
+	PreferredKeyserver types.String               `tfsdk:"preferred_keyserver"`
+	PolicyURI          types.String               `tfsdk:"policy_uri"`
+	Notations          []inspectedNotationModelV1 `tfsdk:"notations"`
+}
+
+type inspectedKeyModelV1 struct {
+	KeyId              types.String               `tfsdk:"key_id"`
+	Fingerprint        types.String               `tfsdk:"fingerprint"`
+	Version            types.Int64                `tfsdk:"version"`
+	CreatedAt          types.String               `tfsdk:"created_at"`
+	PreferredKeyserver types.String               `tfsdk:"preferred_keyserver"`
+	PolicyURI          types.String               `tfsdk:"policy_uri"`
+	Notations          []inspectedNotationModelV1 `tfsdk:"notations"`
+	Identities         []inspectedIdentityModelV1 `tfsdk:"identities"`
+}
+
+// inspectKey collects the subpackets of the self-signatures of the entity. Expiry is not checked and all critical
+// notations are considered known, so that expired keys and keys with critical notations can be inspected as well.
+func inspectKey(entity *openpgp.Entity) (*inspectedKeyModelV1, error) {
+	config := &packet.Config{KnownNotations: map[string]bool{}}
+	for _, direct := range entity.DirectSignatures {
+		for _, notation := range direct.Packet.Notations {
+			config.KnownNotations[notation.Name] = true
+		}
+	}
+	for _, identity := range entity.Identities {
+		for _, selfCertification := range identity.SelfCertifications {
+			for _, notation := range selfCertification.Packet.Notations {
+				config.KnownNotations[notation.Name] = true
+			}
+		}
+	}
+
+	primarySig, err := entity.PrimarySelfSignature(time.Time{}, config)
+	if err != nil {
+		return nil, err
+	}
+
+	inspection := &inspectedKeyModelV1{
+		KeyId:              types.StringValue(entity.PrimaryKey.KeyIdString()),
+		Fingerprint:        types.StringValue(hex.EncodeToString(entity.PrimaryKey.Fingerprint)),
+		Version:            types.Int64Value(int64(entity.PrimaryKey.Version)),
+		CreatedAt:          types.StringValue(entity.PrimaryKey.CreationTime.UTC().Format(time.RFC3339)),
+		PreferredKeyserver: optionalString(primarySig.PreferredKeyserver),
+		PolicyURI:          optionalString(primarySig.PolicyURI),
+		Notations:          inspectNotations(primarySig),
+		Identities:         []inspectedIdentityModelV1{},
+	}
+
+	userIds := make([]string, 0, len(entity.Identities))
+	for userId := range entity.Identities {
+		userIds = append(userIds, userId)
+	}
+	sort.Strings(userIds)
+	for _, userId := range userIds {
+		identity := entity.Identities[userId]
+		sig, err := identity.LatestValidSelfCertification(time.Time{}, config)
+		if err != nil {
+			continue
+		}
+		inspection.Identities = append(inspection.Identities, inspectedIdentityModelV1{
+			UserId:             types.StringValue(userId),
+			Name:               types.StringValue(identity.UserId.Name),
+			Email:              types.StringValue(identity.UserId.Email),
+			PreferredKeyserver: optionalString(sig.PreferredKeyserver),
+			PolicyURI:          optionalString(sig.PolicyURI),
+			Notations:          inspectNotations(sig),
+		})
+	}
+	return inspection, nil
+}
+
+// inspectNotations returns the notations of the signature without the salt notation of randomized v4 signatures.
+func inspectNotations(sig *packet.Signature) []inspectedNotationModelV1 {
+	notations := []inspectedNotationModelV1{}
+	for _, notation := range sig.Notations {
+		if notation.Name == packet.SaltNotationName {
+			continue
+		}
+		value := string(notation.Value)
+		if !notation.IsHumanReadable {
+			value = hex.EncodeToString(notation.Value)
+		}
+		notations = append(notations, inspectedNotationModelV1{
+			Name:          types.StringValue(notation.Name),
+			Value:         types.StringValue(value),
+			HumanReadable: types.BoolValue(notation.IsHumanReadable),
+			Critical:      types.BoolValue(notation.IsCritical),
+		})
+	}
+	return notations
+}
+
+// optionalString returns null for empty strings.
+func optionalString(value string) types.String {
+	if value == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(value)
+}
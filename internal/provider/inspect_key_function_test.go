@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccInspectKeyFunction(t *testing.T) {
+	keyNotations := []knownvalue.Check{
+		knownvalue.ObjectExact(map[string]knownvalue.Check{
+			"name":           knownvalue.StringExact("team@example.com"),
+			"value":          knownvalue.StringExact("platform"),
+			"human_readable": knownvalue.Bool(true),
+			"critical":       knownvalue.Bool(false),
+		}),
+		knownvalue.ObjectExact(map[string]knownvalue.Check{
+			"name":           knownvalue.StringExact("blob@example.com"),
+			"value":          knownvalue.StringExact("cafe"),
+			"human_readable": knownvalue.Bool(false),
+			"critical":       knownvalue.Bool(false),
+		}),
+	}
+	ticketNotation := knownvalue.ObjectExact(map[string]knownvalue.Check{
+		"name":           knownvalue.StringExact("ticket@example.com"),
+		"value":          knownvalue.StringExact("OPS-1234"),
+		"human_readable": knownvalue.Bool(true),
+		"critical":       knownvalue.Bool(true),
+	})
+
+	for profile, expected := range map[string]struct {
+		keyNotations      []knownvalue.Check
+		identityNotations []knownvalue.Check
+		identitySubpacket func(value string) knownvalue.Check
+	}{
+		// v4 keys carry the key properties in the user ID self-signature, so both are the same.
+		"gnupg": {
+			keyNotations:      append(keyNotations, ticketNotation),
+			identityNotations: append(keyNotations, ticketNotation),
+			identitySubpacket: func(value string) knownvalue.Check { return knownvalue.StringExact(value) },
+		},
+		// v6 keys carry the key properties in the direct key signature.
+		"rfc9580": {
+			keyNotations:      keyNotations,
+			identityNotations: []knownvalue.Check{ticketNotation},
+			identitySubpacket: func(string) knownvalue.Check { return knownvalue.Null() },
+		},
+	} {
+		t.Run(profile, func(t *testing.T) {
+			resource.Test(t, resource.TestCase{
+				PreCheck: func() { testAccPreCheck(t) },
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_8_0),
+				},
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config:      testAccInspectKeyFunctionConfig(profile, "team"),
+						ExpectError: regexp.MustCompile(`must be in the format name@domain`),
+					},
+					{
+						Config: testAccInspectKeyFunctionConfig(profile, "team@example.com"),
+						ConfigStateChecks: []statecheck.StateCheck{
+							statecheck.ExpectKnownOutputValue("inspection", knownvalue.ObjectPartial(map[string]knownvalue.Check{
+								"preferred_keyserver": knownvalue.StringExact("hkps://keys.example.com"),
+								"policy_uri":          knownvalue.StringExact("https://example.com/pgp-policy"),
+								"notations":           knownvalue.ListExact(expected.keyNotations),
+								"identities": knownvalue.ListExact([]knownvalue.Check{
+									knownvalue.ObjectExact(map[string]knownvalue.Check{
+										"user_id":             knownvalue.StringExact("John Doe <john.doe@example.com>"),
+										"name":                knownvalue.StringExact("John Doe"),
+										"email":               knownvalue.StringExact("john.doe@example.com"),
+										"preferred_keyserver": expected.identitySubpacket("hkps://keys.example.com"),
+										"policy_uri":          expected.identitySubpacket("https://example.com/pgp-policy"),
+										"notations":           knownvalue.ListExact(expected.identityNotations),
+									}),
+								}),
+							})),
+						},
+						Check: resource.ComposeAggregateTestCheckFunc(
+							testAccCheckOutputEqualsAttr("fingerprint", "gpg_key_pair.test", "fingerprint"),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func testAccInspectKeyFunctionConfig(profile string, name string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+  profile    = %[1]q
+  notations = [{
+    name  = %[2]q
+    value = "platform"
+  }, {
+    name           = "blob@example.com"
+    value          = "cafe"
+    human_readable = false
+  }, {
+    name     = "ticket@example.com"
+    value    = "OPS-1234"
+    critical = true
+    email    = "john.doe@example.com"
+  }]
+  preferred_keyserver = "hkps://keys.example.com"
+  policy_uri          = "https://example.com/pgp-policy"
+}
+
+locals {
+  inspection = provider::gpg::inspect_key(gpg_key_pair.test.public_key)
+}
+
+output "inspection" {
+  value = local.inspection
+}
+
+output "fingerprint" {
+  value = local.inspection.fingerprint
+}
+`, profile, name)
+}
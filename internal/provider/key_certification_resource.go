@@ -0,0 +1,383 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v3/armor"
+	"github.com/ProtonMail/gopenpgp/v3/constants"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &KeyCertificationResource{}
+var _ resource.ResourceWithConfigure = &KeyCertificationResource{}
+var _ resource.ResourceWithValidateConfig = &KeyCertificationResource{}
+
+// certificationLevels maps the certification levels to their signature types.
+var certificationLevels = map[string]packet.SignatureType{
+	"generic":  packet.SigTypeGenericCert,
+	"persona":  packet.SigTypePersonaCert,
+	"casual":   packet.SigTypeCasualCert,
+	"positive": packet.SigTypePositiveCert,
+}
+
+// fullTrustAmount is the trust amount of trust signatures, 120 denotes complete trust.
+const fullTrustAmount = 120
+
+func NewKeyCertificationResource() resource.Resource {
+	return &KeyCertificationResource{}
+}
+
+type KeyCertificationResource struct {
+	// defaults holds the provider configuration, it is nil if the provider has not been configured.
+	defaults *GpgProviderModel
+}
+
+func (g *KeyCertificationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	defaults, ok := req.ProviderData.(*GpgProviderModel)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *GpgProviderModel, got %T.", req.ProviderData))
+		return
+	}
+	g.defaults = defaults
+}
+
+func (g KeyCertificationResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_key_certification"
+}
+
+func (g KeyCertificationResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A resource for certifying the user IDs of a public key with another key",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprints of the signer and the target key separated by a colon.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"signer_private_key": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private key of the signer in armored format.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"signer_passphrase": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Passphrase for unlocking the private key of the signer.",
+			},
+			"public_key": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Public key to certify in armored format.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"uid": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "User ID to certify, e.g. `John Doe <john.doe@example.com>`. Defaults to all user IDs of the key.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"level": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: fmt.Sprintf("Certification level, one of %q. Defaults to `generic`.", certificationLevelNames()),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"trust_depth": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Depth of a trust signature. If set, the certification also delegates full trust to the certified key, with `1` making it a trusted introducer.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"trust_regex": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Regular expression limiting the user IDs the trust signature applies to, e.g. `<[^>]+[@.]example\\.com>$`. Requires `trust_depth`.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"expiry": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Expiry of the certification as duration, e.g. `8760h`. Defaults to a certification that does not expire.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"signer_fingerprint": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprint of the signer key.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"fingerprint": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprint of the certified key.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"certified_public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key in armored format with the new certifications merged.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"certified_public_key_hex": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key in hex format with the new certifications merged.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (g KeyCertificationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data keyCertificationModelV1
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Level.IsNull() && !data.Level.IsUnknown() {
+		if _, ok := certificationLevels[data.Level.ValueString()]; !ok {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("level"),
+				"Invalid certification level",
+				fmt.Sprintf("Unknown certification level %q, expected one of %q.", data.Level.ValueString(), certificationLevelNames()),
+			)
+		}
+	}
+	if !data.TrustDepth.IsNull() && !data.TrustDepth.IsUnknown() && (data.TrustDepth.ValueInt64() < 1 || data.TrustDepth.ValueInt64() > 255) {
+		resp.Diagnostics.AddAttributeError(path.Root("trust_depth"), "Invalid trust depth", "The trust depth must be between 1 and 255.")
+	}
+	if !data.TrustRegex.IsNull() && data.TrustDepth.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("trust_regex"), "Missing trust depth", "A trust regular expression requires trust_depth to be set.")
+	}
+	if !data.Expiry.IsNull() && !data.Expiry.IsUnknown() {
+		if _, err := parseExpiry(data.Expiry.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("expiry"), "Invalid expiry", err.Error())
+		}
+	}
+}
+
+func (g KeyCertificationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data keyCertificationModelV1
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var defaults GpgProviderModel
+	if g.defaults != nil {
+		defaults = *g.defaults
+	}
+
+	keyProfile, err := profileByName(resolveString(types.StringNull(), defaults.DefaultProfile, defaultProfileName).ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("GPG key certification failed", err.Error())
+		return
+	}
+	config := keyProfile.SignConfig()
+
+	signers, err := openpgp.ReadArmoredKeyRing(strings.NewReader(data.SignerPrivateKey.ValueString()))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("signer_private_key"), "GPG key certification failed", fmt.Sprintf("ReadArmoredKeyRing failed with error: %s", err))
+		return
+	}
+	if len(signers) != 1 || signers[0].PrivateKey == nil {
+		resp.Diagnostics.AddAttributeError(path.Root("signer_private_key"), "GPG key certification failed", "Expected exactly one private key.")
+		return
+	}
+	signer := signers[0]
+
+	passphrase := data.SignerPassphrase.ValueString()
+	if err := signer.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("signer_passphrase"), "GPG key certification failed", fmt.Sprintf("DecryptPrivateKeys failed with error: %s", err))
+		return
+	}
+
+	certificationKey, ok := signer.CertificationKey(config.Now())
+	if !ok {
+		resp.Diagnostics.AddAttributeError(path.Root("signer_private_key"), "GPG key certification failed", "The signer has no valid certification key.")
+		return
+	}
+
+	targets, err := openpgp.ReadArmoredKeyRing(strings.NewReader(data.PublicKey.ValueString()))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("public_key"), "GPG key certification failed", fmt.Sprintf("ReadArmoredKeyRing failed with error: %s", err))
+		return
+	}
+	if len(targets) != 1 {
+		resp.Diagnostics.AddAttributeError(path.Root("public_key"), "GPG key certification failed", fmt.Sprintf("Expected exactly one public key, got %d.", len(targets)))
+		return
+	}
+	target := targets[0]
+
+	var uids []string
+	if data.Uid.IsNull() {
+		for uid := range target.Identities {
+			uids = append(uids, uid)
+		}
+		sort.Strings(uids)
+	} else {
+		if _, ok := target.Identities[data.Uid.ValueString()]; !ok {
+			resp.Diagnostics.AddAttributeError(path.Root("uid"), "GPG key certification failed", fmt.Sprintf("The key has no user ID %q.", data.Uid.ValueString()))
+			return
+		}
+		uids = []string{data.Uid.ValueString()}
+	}
+
+	level := resolveString(data.Level, types.StringNull(), "generic").ValueString()
+
+	var lifetime int32
+	if !data.Expiry.IsNull() {
+		if lifetime, err = parseExpiry(data.Expiry.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("expiry"), "GPG key certification failed", err.Error())
+			return
+		}
+	}
+
+	err = certifyUserIds(target, uids, certificationKey, certificationLevels[level], time.Now(), config, func(sig *packet.Signature) {
+		if lifetime > 0 {
+			sigLifetimeSecs := uint32(lifetime)
+			sig.SigLifetimeSecs = &sigLifetimeSecs
+		}
+		if !data.TrustDepth.IsNull() {
+			sig.TrustLevel = packet.TrustLevel(data.TrustDepth.ValueInt64())
+			sig.TrustAmount = fullTrustAmount
+			if !data.TrustRegex.IsNull() {
+				regex := data.TrustRegex.ValueString()
+				sig.TrustRegularExpression = &regex
+			}
+		}
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("GPG key certification failed", fmt.Sprintf("SignUserId failed with error: %s", err))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := target.Serialize(&buf); err != nil {
+		resp.Diagnostics.AddError("GPG key certification failed", fmt.Sprintf("Serialize failed with error: %s", err))
+		return
+	}
+
+	certifiedPublicKey, err := armor.ArmorWithTypeAndCustomHeaders(buf.Bytes(), constants.PublicKeyHeader, "", resolveString(types.StringNull(), defaults.DefaultComment, "").ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("GPG key certification failed", fmt.Sprintf("Armor failed with error: %s", err))
+		return
+	}
+
+	signerFingerprint := hex.EncodeToString(signer.PrimaryKey.Fingerprint)
+	fingerprint := hex.EncodeToString(target.PrimaryKey.Fingerprint)
+
+	data.Id = types.StringValue(signerFingerprint + ":" + fingerprint)
+	data.SignerFingerprint = types.StringValue(signerFingerprint)
+	data.Fingerprint = types.StringValue(fingerprint)
+	data.CertifiedPublicKey = types.StringValue(certifiedPublicKey)
+	data.CertifiedPublicKeyHex = types.StringValue(hex.EncodeToString(buf.Bytes()))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (g KeyCertificationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Nothing to do here.
+}
+
+// Update ensures the plan value is copied to the state to complete the update.
+func (g KeyCertificationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var model keyCertificationModelV1
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &model)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
+
+func (g KeyCertificationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here.
+}
+
+type keyCertificationModelV1 struct {
+	Id                    types.String `tfsdk:"id"`
+	SignerPrivateKey      types.String `tfsdk:"signer_private_key"`
+	SignerPassphrase      types.String `tfsdk:"signer_passphrase"`
+	PublicKey             types.String `tfsdk:"public_key"`
+	Uid                   types.String `tfsdk:"uid"`
+	Level                 types.String `tfsdk:"level"`
+	TrustDepth            types.Int64  `tfsdk:"trust_depth"`
+	TrustRegex            types.String `tfsdk:"trust_regex"`
+	Expiry                types.String `tfsdk:"expiry"`
+	SignerFingerprint     types.String `tfsdk:"signer_fingerprint"`
+	Fingerprint           types.String `tfsdk:"fingerprint"`
+	CertifiedPublicKey    types.String `tfsdk:"certified_public_key"`
+	CertifiedPublicKeyHex types.String `tfsdk:"certified_public_key_hex"`
+}
+
+// certificationLevelNames returns the certification levels in ascending order.
+func certificationLevelNames() []string {
+	return []string{"generic", "persona", "casual", "positive"}
+}
+
+// certifyUserIds certifies the user IDs of the target with the certification key by signatures of the type created at
+// the time. Options such as the expiry or trust are set on each signature by customize before it is signed, if not nil.
+func certifyUserIds(target *openpgp.Entity, uids []string, certificationKey openpgp.Key, sigType packet.SignatureType, now time.Time, config *packet.Config, customize func(sig *packet.Signature)) error {
+	for _, uid := range uids {
+		sig := &packet.Signature{
+			Version:           certificationKey.PublicKey.Version,
+			SigType:           sigType,
+			PubKeyAlgo:        certificationKey.PublicKey.PubKeyAlgo,
+			Hash:              config.Hash(),
+			CreationTime:      now,
+			IssuerKeyId:       &certificationKey.PublicKey.KeyId,
+			IssuerFingerprint: certificationKey.PublicKey.Fingerprint,
+		}
+		if customize != nil {
+			customize(sig)
+		}
+		if err := sig.SignUserId(uid, target.PrimaryKey, certificationKey.PrivateKey, config); err != nil {
+			return err
+		}
+		target.Identities[uid].Signatures = append(target.Identities[uid].Signatures, sig)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccKeyCertificationResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccKeyCertificationResourceConfig("casual", 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("gpg_key_certification.test", "signer_fingerprint", "gpg_key_pair.ca", "fingerprint"),
+					resource.TestCheckResourceAttrPair("gpg_key_certification.test", "fingerprint", "gpg_key_pair.engineer", "fingerprint"),
+					testAccCheckGpgKeyCertification("gpg_key_certification.test", packet.SigTypeCasualCert, 1),
+				),
+			},
+			// Update testing, changing the level creates a new certification
+			{
+				Config: testAccKeyCertificationResourceConfig("positive", 2),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgKeyCertification("gpg_key_certification.test", packet.SigTypePositiveCert, 2),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccCheckGpgKeyCertification(name string, sigType packet.SignatureType, trustDepth int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+
+		signers, err := openpgp.ReadArmoredKeyRing(strings.NewReader(rs.Primary.Attributes["signer_private_key"]))
+		if err != nil {
+			return err
+		}
+		keys, err := openpgp.ReadArmoredKeyRing(strings.NewReader(rs.Primary.Attributes["certified_public_key"]))
+		if err != nil {
+			return err
+		}
+
+		identity := keys[0].Identities["Jane Doe <jane.doe@example.com>"]
+		if identity == nil {
+			return fmt.Errorf("expected identity to be present")
+		}
+		for _, sig := range identity.Signatures {
+			if !sig.CheckKeyIdOrFingerprint(signers[0].PrimaryKey) {
+				continue
+			}
+			if err := signers[0].PrimaryKey.VerifyUserIdSignature(identity.Name, keys[0].PrimaryKey, sig); err != nil {
+				return err
+			}
+			if sig.SigType != sigType {
+				return fmt.Errorf("unexpected signature type %d", sig.SigType)
+			}
+			if int(sig.TrustLevel) != trustDepth || sig.TrustAmount != fullTrustAmount {
+				return fmt.Errorf("unexpected trust level %d and amount %d", sig.TrustLevel, sig.TrustAmount)
+			}
+			if sig.TrustRegularExpression == nil || *sig.TrustRegularExpression != "<[^>]+[@.]example\\.com>$" {
+				return fmt.Errorf("unexpected trust regular expression")
+			}
+			return nil
+		}
+		return fmt.Errorf("expected a certification by the signer")
+	}
+}
+
+func testAccKeyCertificationResourceConfig(level string, trustDepth int) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "ca" {
+  identities = [{
+	name  = "Example CA"
+	email = "ca@example.com"
+  }]
+  passphrase = "top secret"
+}
+
+resource "gpg_key_pair" "engineer" {
+  identities = [{
+	name  = "Jane Doe"
+	email = "jane.doe@example.com"
+  }]
+  passphrase = "top secret"
+}
+
+resource "gpg_key_certification" "test" {
+  signer_private_key = gpg_key_pair.ca.private_key
+  signer_passphrase  = gpg_key_pair.ca.passphrase
+  public_key         = gpg_key_pair.engineer.public_key
+  level              = %[1]q
+  trust_depth        = %[2]d
+  trust_regex        = "<[^>]+[@.]example\\.com>$"
+  expiry             = "8760h"
+}
+`, level, trustDepth)
+}
@@ -0,0 +1,242 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ ephemeral.EphemeralResource = &KeyPairEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigure = &KeyPairEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithValidateConfig = &KeyPairEphemeralResource{}
+
+func NewKeyPairEphemeralResource() ephemeral.EphemeralResource {
+	return &KeyPairEphemeralResource{}
+}
+
+type KeyPairEphemeralResource struct {
+	// defaults holds the provider configuration, it is nil if the provider has not been configured.
+	defaults *GpgProviderModel
+}
+
+func (g *KeyPairEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	defaults, ok := req.ProviderData.(*GpgProviderModel)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *GpgProviderModel, got %T.", req.ProviderData))
+		return
+	}
+	g.defaults = defaults
+}
+
+func (g KeyPairEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_key_pair"
+}
+
+func (g KeyPairEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "An ephemeral resource for generating GPG keys that are never stored in the plan or state. A new key is generated on every run, so the keys should be passed to write-only attributes of other resources.",
+		Attributes: map[string]schema.Attribute{
+			"identities": schema.ListNestedAttribute{
+				Description: "List of identities for the GPG key pair.",
+				Required:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "Name",
+							Required:    true,
+						},
+						"email": schema.StringAttribute{
+							Description: "Email",
+							Required:    true,
+						},
+					},
+				},
+			},
+			"passphrase": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Passphrase for locking the private key.",
+			},
+			"profile": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: fmt.Sprintf("Algorithm profile used for generating the key, one of %q. Defaults to the provider's `default_profile` or `%s`.", profileNames(), defaultProfileName),
+			},
+			"expiry": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Expiry of the key as duration, e.g. `17520h`. `0` means that the key does not expire. Defaults to the provider's `default_expiry` or `0`.",
+			},
+			"s2k": schema.SingleNestedAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "String-to-key settings for locking the private key. Defaults to the provider's `default_s2k` or the settings of the profile.",
+				Attributes: map[string]schema.Attribute{
+					"mode": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: fmt.Sprintf("S2K mode, either `%s` or `%s`.", s2kModeIterated, s2kModeArgon2),
+					},
+					"count": schema.Int64Attribute{
+						Optional:            true,
+						Computed:            true,
+						MarkdownDescription: fmt.Sprintf("Iteration count for the `%s` mode.", s2kModeIterated),
+					},
+					"argon2_passes": schema.Int64Attribute{
+						Optional:            true,
+						Computed:            true,
+						MarkdownDescription: fmt.Sprintf("Number of passes for the `%s` mode.", s2kModeArgon2),
+					},
+					"argon2_parallelism": schema.Int64Attribute{
+						Optional:            true,
+						Computed:            true,
+						MarkdownDescription: fmt.Sprintf("Degree of parallelism for the `%s` mode.", s2kModeArgon2),
+					},
+					"argon2_memory": schema.Int64Attribute{
+						Optional:            true,
+						Computed:            true,
+						MarkdownDescription: fmt.Sprintf("Memory in KiB for the `%s` mode.", s2kModeArgon2),
+					},
+				},
+			},
+			"authentication_subkey": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether to add an authentication subkey, e.g. for using the key with gpg-agent as ssh-agent. Not supported with the `rfc9580` profile, as SSH does not support Ed448 keys. Defaults to `false`.",
+			},
+			"encrypt_ssh_private_key": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether to encrypt `ssh_private_key` with `passphrase` using bcrypt-pbkdf. Defaults to `true`.",
+			},
+			"comment": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Comment header of the armored keys. Defaults to the provider's `default_comment`.",
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "ID of the key pair in hex format.",
+			},
+			"fingerprint": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprint of the public key.",
+			},
+			"private_key": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private key in armored format.",
+			},
+			"private_key_hex": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private key in hex format.",
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key in armored format.",
+			},
+			"public_key_hex": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key in hex format.",
+			},
+			"paperkey": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Secret parts of the private key in paperkey text format for printing, see the `to_paperkey` function. Null for keys other than version 4.",
+			},
+			"ssh_public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Authentication key in OpenSSH `authorized_keys` format, null if the key has no authentication key.",
+			},
+			"ssh_private_key": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private authentication key in OpenSSH format, encrypted with `passphrase` unless `encrypt_ssh_private_key` is `false`. Null if the key has no authentication key. Only Ed25519 and RSA keys are supported.",
+			},
+		},
+	}
+}
+
+func (g KeyPairEphemeralResource) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var data keyPairEphemeralModelV1
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateKeyPairSettings(ctx, data.Identities, data.Profile, data.Expiry, data.S2K)...)
+	resp.Diagnostics.Append(validateAuthenticationSubkey(g.defaults, data.Profile, data.AuthenticationSubkey)...)
+}
+
+func (g KeyPairEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data keyPairEphemeralModelV1
+
+	// Read Terraform config data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	settings := keyPairGeneration{
+		Identities:           data.Identities,
+		Passphrase:           data.Passphrase.ValueString(),
+		Profile:              data.Profile,
+		Expiry:               data.Expiry,
+		S2K:                  data.S2K,
+		AuthenticationSubkey: data.AuthenticationSubkey.ValueBool(),
+		ExportSSHPrivateKey:  true,
+		EncryptSSHPrivateKey: encryptSSHPrivateKey(data.EncryptSSHPrivateKey),
+		Comment:              data.Comment,
+	}
+	keys, diags := generateKeyPair(ctx, g.defaults, &settings)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Profile = settings.Profile
+	data.Expiry = settings.Expiry
+	data.S2K = settings.S2K
+	data.Comment = settings.Comment
+	data.Id = keys.Id
+	data.Fingerprint = keys.Fingerprint
+	data.PrivateKey = keys.PrivateKey
+	data.PrivateKeyHex = keys.PrivateKeyHex
+	data.PublicKey = keys.PublicKey
+	data.PublicKeyHex = keys.PublicKeyHex
+	data.Paperkey = keys.Paperkey
+	data.SSHPublicKey = keys.SSHPublicKey
+	data.SSHPrivateKey = keys.SSHPrivateKey
+
+	// Save data into the ephemeral result
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+type keyPairEphemeralModelV1 struct {
+	Identities           []identityModelV1 `tfsdk:"identities"`
+	Passphrase           types.String      `tfsdk:"passphrase"`
+	Profile              types.String      `tfsdk:"profile"`
+	Expiry               types.String      `tfsdk:"expiry"`
+	S2K                  types.Object      `tfsdk:"s2k"`
+	AuthenticationSubkey types.Bool        `tfsdk:"authentication_subkey"`
+	EncryptSSHPrivateKey types.Bool        `tfsdk:"encrypt_ssh_private_key"`
+	Comment              types.String      `tfsdk:"comment"`
+	Id                   types.String      `tfsdk:"id"`
+	Fingerprint          types.String      `tfsdk:"fingerprint"`
+	PrivateKey           types.String      `tfsdk:"private_key"`
+	PrivateKeyHex        types.String      `tfsdk:"private_key_hex"`
+	PublicKey            types.String      `tfsdk:"public_key"`
+	PublicKeyHex         types.String      `tfsdk:"public_key_hex"`
+	Paperkey             types.String      `tfsdk:"paperkey"`
+	SSHPublicKey         types.String      `tfsdk:"ssh_public_key"`
+	SSHPrivateKey        types.String      `tfsdk:"ssh_private_key"`
+}
@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccKeyPairEphemeralResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccKeyPairEphemeralResourceConfig(),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("fingerprint"), knownvalue.StringRegexp(regexp.MustCompile(`^[0-9a-f]{40}$`))),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("profile"), knownvalue.StringExact(defaultProfileName)),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("ssh_public_key"), knownvalue.StringRegexp(regexp.MustCompile(`^ssh-ed25519 `))),
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckEchoKeyPair("echo.test", "top secret"),
+				),
+			},
+		},
+	})
+}
+
+func testAccKeyPairEphemeralResourceConfig() string {
+	return fmt.Sprintf(`
+ephemeral "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase            = %q
+  authentication_subkey = true
+}
+
+provider "echo" {
+  data = ephemeral.gpg_key_pair.test
+}
+
+resource "echo" "test" {}
+`, "top secret")
+}
+
+// testAccCheckEchoKeyPair checks that the private key echoed by the echo provider is locked with the passphrase.
+func testAccCheckEchoKeyPair(name string, passphrase string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+		privateKey, err := crypto.NewKeyFromArmored(rs.Primary.Attributes["data.private_key"])
+		if err != nil {
+			return err
+		}
+
+		unlocked, err := privateKey.Unlock([]byte(passphrase))
+		if err != nil {
+			return err
+		}
+		if unlocked.GetFingerprint() != rs.Primary.Attributes["data.fingerprint"] {
+			return fmt.Errorf("unexpected fingerprint %s", unlocked.GetFingerprint())
+		}
+		return nil
+	}
+}
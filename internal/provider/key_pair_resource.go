@@ -1,34 +1,61 @@
 package provider
 
 import (
+	"bytes"
 	"context"
 	"crypto"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
 	"github.com/ProtonMail/gopenpgp/v3/constants"
 	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
 	"github.com/ProtonMail/gopenpgp/v3/profile"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"unsafe"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"io"
+	"time"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &KeyPairResource{}
+var _ resource.ResourceWithConfigure = &KeyPairResource{}
 var _ resource.ResourceWithValidateConfig = &KeyPairResource{}
+var _ resource.ResourceWithModifyPlan = &KeyPairResource{}
 var _ resource.ResourceWithMoveState = &KeyPairResource{}
+var _ resource.ResourceWithUpgradeState = &KeyPairResource{}
 
 func NewKeyPairResource() resource.Resource {
 	return &KeyPairResource{}
 }
 
 type KeyPairResource struct {
+	// defaults holds the provider configuration, it is nil if the provider has not been configured.
+	defaults *GpgProviderModel
+}
+
+func (g *KeyPairResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	defaults, ok := req.ProviderData.(*GpgProviderModel)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *GpgProviderModel, got %T.", req.ProviderData))
+		return
+	}
+	g.defaults = defaults
 }
 
 func (g KeyPairResource) MoveState(ctx context.Context) []resource.StateMover {
@@ -45,14 +72,74 @@ func (g KeyPairResource) moveStateResourceKey(ctx context.Context, req resource.
 	if req.SourceTypeName != "gpg_key" {
 		return
 	}
-	var model keyPairModelV1
+	var model keyModelV1
 	resp.Diagnostics.Append(req.SourceState.Get(ctx, &model)...)
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	resp.Diagnostics.Append(resp.TargetState.Set(ctx, &model)...)
+	target, diags := upgradeKeyModelV1(ctx, model)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.TargetState.Set(ctx, &target)...)
+}
+
+func (g KeyPairResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema:   keySchema(),
+			StateUpgrader: g.upgradeStateV0,
+		},
+	}
+}
+
+// upgradeStateV0 adds the key generation settings introduced in version 1 with the values used before.
+func (g KeyPairResource) upgradeStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	var model keyModelV1
+	resp.Diagnostics.Append(req.State.Get(ctx, &model)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	target, diags := upgradeKeyModelV1(ctx, model)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &target)...)
+}
+
+// upgradeKeyModelV1 converts a key without key generation settings, which was generated with the built-in defaults.
+func upgradeKeyModelV1(ctx context.Context, model keyModelV1) (keyPairModelV1, diag.Diagnostics) {
+	s2kValue, diags := types.ObjectValueFrom(ctx, s2kAttrTypes, resolveS2K(nil, nil, GnuPG()))
+	return keyPairModelV1{
+		Id:                model.Id,
+		Identities:        model.Identities,
+		Passphrase:        model.Passphrase,
+		Profile:           types.StringValue(defaultProfileName),
+		Expiry:            types.StringValue(defaultExpiry),
+		S2K:               s2kValue,
+		Preferences:       types.ObjectNull(preferencesAttrTypes),
+		Comment:           types.StringValue(""),
+		WKDBase:           types.StringValue(""),
+		EscrowRecipients:  types.ListNull(types.StringType),
+		Fingerprint:       model.Fingerprint,
+		Keygrips:          types.ListNull(types.StringType),
+		OpenpgpkeyRecords: types.ListNull(types.ObjectType{AttrTypes: openpgpkeyRecordAttrTypes}),
+		WKDEntries:        types.ListNull(types.ObjectType{AttrTypes: wkdEntryAttrTypes}),
+		PrivateKey:        model.PrivateKey,
+		PrivateKeyHex:     model.PrivateKeyHex,
+		PublicKey:         model.PublicKey,
+		PublicKeyHex:      model.PublicKeyHex,
+	}, diags
 }
 
 func (g KeyPairResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -63,6 +150,7 @@ func (g KeyPairResource) Schema(ctx context.Context, req resource.SchemaRequest,
 	resp.Schema = schema.Schema{
 		// This description is used by the documentation generator and the language server.
 		MarkdownDescription: "A resource for generating ECC (Curve25519) GPG keys",
+		Version:             1,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed:            true,
@@ -91,9 +179,230 @@ func (g KeyPairResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				},
 			},
 			"passphrase": schema.StringAttribute{
-				Required:            true,
+				Optional:            true,
 				Sensitive:           true,
-				MarkdownDescription: "Passphrase for locking the private key.",
+				MarkdownDescription: "Passphrase for locking the private key. Exactly one of `passphrase` and `passphrase_wo` must be set. Changing the passphrase, or switching to `passphrase_wo`, generates a new key locked with the new passphrase.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"passphrase_wo": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				WriteOnly:           true,
+				MarkdownDescription: "Write-only passphrase for locking the private key, which is never stored in the plan or state. Requires Terraform 1.11 or later. Exactly one of `passphrase` and `passphrase_wo` must be set.",
+			},
+			"passphrase_version": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Version of `passphrase_wo`. As the write-only passphrase is not stored, changes to it are only detected by changing this version, which generates a new key locked with the new passphrase.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"profile": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: fmt.Sprintf("Algorithm profile used for generating the key, one of %q. Defaults to the provider's `default_profile` or `%s`.", profileNames(), defaultProfileName),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"deterministic_seed": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: fmt.Sprintf("Seed from which the key material is derived instead of generating it randomly, with the creation time fixed to `%s` unless `creation_time` is set. The same seed and settings always yield the same fingerprint. **For test fixtures only**, the key is only as secret as the seed. RSA keys are not supported.", deterministicCreationTime.Format(time.RFC3339)),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"creation_time": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Creation time of the key and its self-signatures in RFC 3339 format, e.g. `2024-01-01T00:00:00Z`. It must be in the past. A recreated key with the same creation time and key material has the same fingerprint. Defaults to the time of the apply.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"expiry": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Expiry of the key as duration, e.g. `17520h`. `0` means that the key does not expire. Defaults to the provider's `default_expiry` or `0`.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"s2k": schema.SingleNestedAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "String-to-key settings for locking the private key. Defaults to the provider's `default_s2k` or the settings of the profile.",
+				PlanModifiers: []planmodifier.Object{
+					objectplanmodifier.UseStateForUnknown(),
+					objectplanmodifier.RequiresReplace(),
+				},
+				Attributes: map[string]schema.Attribute{
+					"mode": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: fmt.Sprintf("S2K mode, either `%s` or `%s`.", s2kModeIterated, s2kModeArgon2),
+					},
+					"count": schema.Int64Attribute{
+						Optional:            true,
+						Computed:            true,
+						MarkdownDescription: fmt.Sprintf("Iteration count for the `%s` mode.", s2kModeIterated),
+						PlanModifiers: []planmodifier.Int64{
+							int64planmodifier.UseStateForUnknown(),
+						},
+					},
+					"argon2_passes": schema.Int64Attribute{
+						Optional:            true,
+						Computed:            true,
+						MarkdownDescription: fmt.Sprintf("Number of passes for the `%s` mode.", s2kModeArgon2),
+						PlanModifiers: []planmodifier.Int64{
+							int64planmodifier.UseStateForUnknown(),
+						},
+					},
+					"argon2_parallelism": schema.Int64Attribute{
+						Optional:            true,
+						Computed:            true,
+						MarkdownDescription: fmt.Sprintf("Degree of parallelism for the `%s` mode.", s2kModeArgon2),
+						PlanModifiers: []planmodifier.Int64{
+							int64planmodifier.UseStateForUnknown(),
+						},
+					},
+					"argon2_memory": schema.Int64Attribute{
+						Optional:            true,
+						Computed:            true,
+						MarkdownDescription: fmt.Sprintf("Memory in KiB for the `%s` mode.", s2kModeArgon2),
+						PlanModifiers: []planmodifier.Int64{
+							int64planmodifier.UseStateForUnknown(),
+						},
+					},
+				},
+			},
+			"preferences": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Algorithm preferences advertised in the self-signatures of the key, e.g. to drop legacy algorithms. Unset preferences keep the defaults of the profile.",
+				PlanModifiers: []planmodifier.Object{
+					objectplanmodifier.RequiresReplace(),
+				},
+				Attributes: map[string]schema.Attribute{
+					"ciphers": schema.ListAttribute{
+						ElementType:         types.StringType,
+						Optional:            true,
+						MarkdownDescription: fmt.Sprintf("Preferred symmetric ciphers in order of preference, of %q.", sortedKeys(preferredCiphers)),
+					},
+					"hashes": schema.ListAttribute{
+						ElementType:         types.StringType,
+						Optional:            true,
+						MarkdownDescription: fmt.Sprintf("Preferred hash algorithms in order of preference, of %q.", sortedKeys(preferredHashes)),
+					},
+					"compression": schema.ListAttribute{
+						ElementType:         types.StringType,
+						Optional:            true,
+						MarkdownDescription: fmt.Sprintf("Preferred compression algorithms in order of preference, of %q.", sortedKeys(preferredCompressions)),
+					},
+					"aead_ciphersuites": schema.ListAttribute{
+						ElementType:         types.StringType,
+						Optional:            true,
+						MarkdownDescription: fmt.Sprintf("Preferred AEAD ciphersuites in order of preference as `<cipher>/<mode>`, e.g. `aes256/ocb`, with a cipher of %q and a mode of %q. Setting them advertises the `%s` feature unless `features` is set.", aeadCiphers, sortedKeys(aeadModes), featureSEIPDv2),
+					},
+					"features": schema.ListAttribute{
+						ElementType:         types.StringType,
+						Optional:            true,
+						MarkdownDescription: fmt.Sprintf("Supported features, of `%s` (SEIPDv1) and `%s`. `aead_ciphersuites` require `%s`.", featureMDC, featureSEIPDv2, featureSEIPDv2),
+					},
+				},
+			},
+			"notations": schema.ListNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Notation data added to the self-signatures of the key, e.g. to annotate the owner team or a ticket ID.",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Name of the notation in the format `name@domain`, e.g. `team@example.com`.",
+						},
+						"value": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Value of the notation, in hex format unless `human_readable` is set.",
+						},
+						"human_readable": schema.BoolAttribute{
+							Optional:            true,
+							MarkdownDescription: "Whether the value is text. Defaults to `true`.",
+						},
+						"critical": schema.BoolAttribute{
+							Optional:            true,
+							MarkdownDescription: "Whether the notation is critical, i.e. implementations that do not know it, including GnuPG and this provider, consider the self-signature invalid. Defaults to `false`.",
+						},
+						"email": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "Email of the identity whose self-signature carries the notation. Defaults to the primary key, i.e. all self-signatures carrying the key properties.",
+						},
+					},
+				},
+			},
+			"preferred_keyserver": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "URL of the keyserver from which updates of the key should be fetched.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"policy_uri": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "URI of the policy under which the self-signatures were issued.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"authentication_subkey": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether to add an authentication subkey, e.g. for using the key with gpg-agent as ssh-agent. Not supported with the `rfc9580` profile, as SSH does not support Ed448 keys. Defaults to `false`.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplaceIf(func(ctx context.Context, req planmodifier.BoolRequest, resp *boolplanmodifier.RequiresReplaceIfFuncResponse) {
+						resp.RequiresReplace = req.PlanValue.ValueBool() != req.StateValue.ValueBool()
+					}, "Adding or removing the authentication subkey forces a new key.", "Adding or removing the authentication subkey forces a new key."),
+				},
+			},
+			"export_ssh_private_key": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether to export the private authentication key as `ssh_private_key`. Defaults to `false`.",
+			},
+			"encrypt_ssh_private_key": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether to encrypt `ssh_private_key` with `passphrase` using bcrypt-pbkdf. Defaults to `true`.",
+			},
+			"comment": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Comment header of the armored keys. Defaults to the provider's `default_comment`. Changing the comment only re-armors the keys.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"wkd_base": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Base URL of the Web Key Directory for `wkd_entries`, i.e. the URL of the directory containing `hu`, e.g. `https://openpgpkey.example.com/.well-known/openpgpkey/example.com` for the advanced method or `https://example.com/.well-known/openpgpkey` for the direct method. Defaults to the provider's `default_wkd_base`, also after it is removed from the configuration. Changing the base URL only updates `wkd_entries`.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"escrow_recipients": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Public keys in armored format to which a recovery copy of the private key is encrypted as `escrowed_private_key`. Changing the recipients only encrypts the copy again.",
+			},
+			"created_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Creation time of the primary key in RFC 3339 format.",
+			},
+			"escrowed_private_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Armored private key, still locked with the passphrase, encrypted to `escrow_recipients`, so that the recovery copy can be stored in a less trusted place than the state. Null if no escrow recipients are set.",
 			},
 			"fingerprint": schema.StringAttribute{
 				Computed:            true,
@@ -102,6 +411,51 @@ func (g KeyPairResource) Schema(ctx context.Context, req resource.SchemaRequest,
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"keygrips": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Keygrips of the primary key and each subkey, which identify the keys in gpg-agent, e.g. for `gpg-preset-passphrase`. Null if the key uses an algorithm other than RSA, Ed25519 and Curve25519, e.g. Ed448 of the `rfc9580` profile.",
+			},
+			"openpgpkey_records": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "RFC 7929 OPENPGPKEY DNS records publishing the key for each email address of its validly self-signed user IDs, sorted by email. See the `openpgpkey_record` function.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"email": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Email address published by the record.",
+						},
+						"name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Owner name of the record, the hex-encoded SHA-256 hash of the lowercased local part truncated to 28 octets followed by `._openpgpkey.` and the domain.",
+						},
+						"rdata": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Record data, the minimal public key with only the user IDs of the email address in base64 format.",
+						},
+					},
+				},
+			},
+			"wkd_entries": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Web Key Directory entries publishing the key for each email address of its validly self-signed user IDs, sorted by email. The binary key, e.g. `public_key_base64` decoded, is served at each `url`. Null if `wkd_base` is empty.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"email": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Email address published by the entry.",
+						},
+						"hash": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "File name of the key in the `hu` directory, the z-base-32 encoded SHA-1 hash of the lowercased local part.",
+						},
+						"url": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "URL of the key below `wkd_base` including the `l` parameter with the local part.",
+						},
+					},
+				},
+			},
 			"private_key": schema.StringAttribute{
 				Computed:            true,
 				Sensitive:           true,
@@ -132,6 +486,37 @@ func (g KeyPairResource) Schema(ctx context.Context, req resource.SchemaRequest,
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"public_key_base64": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key in base64 format, e.g. for the `pgp_key` argument of `aws_iam_access_key` and `aws_iam_user_login_profile`.",
+			},
+			"private_key_base64": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private key in base64 format.",
+			},
+			"private_subkeys_only": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private key in armored format without the secret key material of the primary key, like `gpg --export-secret-subkeys`, for machines that only sign and decrypt with the subkeys while the primary key is kept offline. See the `export_secret_subkeys` function.",
+			},
+			"paperkey": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Secret parts of the private key in paperkey text format for printing, see the `to_paperkey` function. Null for keys other than version 4.",
+			},
+			"ssh_public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Authentication key in OpenSSH `authorized_keys` format, null if the key has no authentication key. The key is kept once the authentication key expires.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"ssh_private_key": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private authentication key in OpenSSH format, encrypted with `passphrase` unless `encrypt_ssh_private_key` is `false`. Null unless `export_ssh_private_key` is set or if the key has no authentication key. Only Ed25519 and RSA keys are supported.",
+			},
 		},
 	}
 }
@@ -145,77 +530,265 @@ func (g KeyPairResource) ValidateConfig(ctx context.Context, req resource.Valida
 		return
 	}
 
-	if len(data.Identities) == 0 {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("identities"),
-			"GPG v4 key pairs need at least one identity",
-			"GPG v4 key pairs need at least one identity.",
+	if data.Passphrase.IsNull() == data.PassphraseWO.IsNull() {
+		resp.Diagnostics.AddError("Invalid passphrase", "Exactly one of passphrase and passphrase_wo must be set.")
+	}
+	if !data.PassphraseVersion.IsNull() && data.PassphraseWO.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("passphrase_version"), "Invalid passphrase version", "passphrase_version requires passphrase_wo to be set.")
+	}
+
+	if !data.CreationTime.IsNull() && !data.CreationTime.IsUnknown() {
+		if _, err := parseCreationTime(data.CreationTime.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("creation_time"), "Invalid creation time", err.Error())
+		}
+	}
+
+	if !data.WKDBase.IsNull() && !data.WKDBase.IsUnknown() && data.WKDBase.ValueString() != "" {
+		if err := validateWKDBase(data.WKDBase.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("wkd_base"), "Invalid WKD base URL", err.Error())
+		}
+	}
+
+	if !data.DeterministicSeed.IsNull() {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("deterministic_seed"),
+			"Deterministic key for testing only",
+			"The key material is derived from deterministic_seed and is only as secret as the seed. Use deterministic keys for test fixtures only, never to protect real data.",
 		)
-		return
 	}
+
+	if !data.Preferences.IsNull() && !data.Preferences.IsUnknown() {
+		var preferences preferencesModelV1
+		resp.Diagnostics.Append(data.Preferences.As(ctx, &preferences, basetypes.ObjectAsOptions{})...)
+		if !resp.Diagnostics.HasError() {
+			_, diags := preferences.resolve(ctx)
+			for _, d := range diags {
+				resp.Diagnostics.AddAttributeError(path.Root("preferences"), d.Summary(), d.Detail())
+			}
+		}
+	}
+
+	for i, notation := range data.Notations {
+		if err := notation.validate(data.Identities); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("notations").AtListIndex(i), "Invalid notation", err.Error())
+		}
+	}
+
+	if !data.EscrowRecipients.IsNull() && !data.EscrowRecipients.IsUnknown() {
+		for i, element := range data.EscrowRecipients.Elements() {
+			publicKey, ok := element.(types.String)
+			if !ok || publicKey.IsNull() || publicKey.IsUnknown() {
+				continue
+			}
+			if _, err := gpgcrypto.NewKeyFromArmored(publicKey.ValueString()); err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("escrow_recipients").AtListIndex(i), "Invalid escrow recipient", fmt.Sprintf("NewKeyFromArmored failed with error: %s", err))
+			}
+		}
+	}
+
+	resp.Diagnostics.Append(validateKeyPairSettings(ctx, data.Identities, data.Profile, data.Expiry, data.S2K)...)
+	resp.Diagnostics.Append(validateAuthenticationSubkey(g.defaults, data.Profile, data.AuthenticationSubkey)...)
 }
 
-func (g KeyPairResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var data keyPairModelV1
+// ModifyPlan derives the SSH public key, the creation time and the other formats of the known keys, including the WKD
+// entries once the WKD base or its provider default is known, and re-armors the keys when only the comment changes.
+// The escrowed private key is encrypted again by Update when the escrow recipients change, and the SSH private key is
+// rendered again when it is exported and its encryption changes.
+func (g KeyPairResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
 
-	// Read Terraform plan data into the model
-	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	var plan keyPairModelV1
+	var configWKDBase types.String
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("wkd_base"), &configWKDBase)...)
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	var pgp = gpgcrypto.PGPWithProfile(GnuPG())
+	// An unset WKD base falls back to the provider's default_wkd_base, also after it was removed from the configuration.
+	if configWKDBase.IsNull() {
+		plan.WKDBase = g.resolveWKDBase(configWKDBase)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("wkd_base"), plan.WKDBase)...)
+	}
+
+	if !plan.PublicKeyHex.IsUnknown() && !plan.PublicKeyHex.IsNull() {
+		// The authentication key is chosen among the keys valid now, so a known SSH public key is kept rather than
+		// dropped once its key expires.
+		if plan.SSHPublicKey.IsUnknown() {
+			sshKey, diags := sshPublicKeyFromHex(plan.PublicKeyHex.ValueString())
+			resp.Diagnostics.Append(diags...)
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("ssh_public_key"), sshKey)...)
+		}
 
-	builder := pgp.KeyGeneration()
-	for _, identity := range data.Identities {
-		builder = builder.AddUserId(identity.Name.ValueString(), identity.Email.ValueString())
+		createdAt, diags := createdAtFromHex(plan.PublicKeyHex.ValueString())
+		resp.Diagnostics.Append(diags...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("created_at"), createdAt)...)
+
+		grips, diags := keygripsFromHex(ctx, plan.PublicKeyHex.ValueString())
+		resp.Diagnostics.Append(diags...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("keygrips"), grips)...)
+
+		records, diags := openpgpkeyRecordsFromHex(ctx, plan.PublicKeyHex.ValueString())
+		resp.Diagnostics.Append(diags...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("openpgpkey_records"), records)...)
+
+		publicKeyBase64, diags := base64FromHex(plan.PublicKeyHex.ValueString())
+		resp.Diagnostics.Append(diags...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("public_key_base64"), publicKeyBase64)...)
+
+		if !plan.WKDBase.IsUnknown() {
+			entries, diags := wkdEntriesFromHex(ctx, plan.PublicKeyHex.ValueString(), plan.WKDBase.ValueString())
+			resp.Diagnostics.Append(diags...)
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("wkd_entries"), entries)...)
+		}
 	}
+	if !plan.PrivateKeyHex.IsUnknown() && !plan.PrivateKeyHex.IsNull() {
+		paperkey, diags := paperkeyFromHex(plan.PrivateKeyHex.ValueString())
+		resp.Diagnostics.Append(diags...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("paperkey"), paperkey)...)
 
-	key, err := builder.New().GenerateKeyWithSecurity(constants.HighSecurity)
+		privateKeyBase64, diags := base64FromHex(plan.PrivateKeyHex.ValueString())
+		resp.Diagnostics.Append(diags...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("private_key_base64"), privateKeyBase64)...)
 
-	if err != nil {
-		resp.Diagnostics.AddError("GPG key pair generation failed", fmt.Sprintf("GenerateKeyWithSecurity failed with error: %s", err))
-		return
+		if !plan.Comment.IsUnknown() {
+			subkeysOnly, diags := privateSubkeysOnlyFromHex(plan.PrivateKeyHex.ValueString(), plan.Comment.ValueString())
+			resp.Diagnostics.Append(diags...)
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("private_subkeys_only"), subkeysOnly)...)
+		}
+	}
+	if plan.EscrowRecipients.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("escrowed_private_key"), types.StringNull())...)
+	}
+	if !plan.ExportSSHPrivateKey.IsUnknown() && !plan.ExportSSHPrivateKey.ValueBool() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("ssh_private_key"), types.StringNull())...)
 	}
-	defer key.ClearPrivateParams()
 
-	key, err = pgp.LockKey(key, unsafe.Slice(unsafe.StringData(data.Passphrase.ValueString()), len(data.Passphrase.ValueString())))
-	if err != nil {
-		resp.Diagnostics.AddError("GPG key pair generation failed", fmt.Sprintf("LockKey failed with error: %s", err))
+	if req.State.Raw.IsNull() {
 		return
 	}
 
-	privateKey, err := key.Armor()
-	if err != nil {
-		resp.Diagnostics.AddError("GPG key pair generation failed", fmt.Sprintf("Armor failed with error: %s", err))
+	var state keyPairModelV1
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	privateKeyHex, err := key.Serialize()
-	if err != nil {
-		resp.Diagnostics.AddError("GPG key pair generation failed", fmt.Sprintf("Serialize failed with error: %s", err))
-		return
+	// The SSH private key is rendered again by Update if it was not exported before or its encryption changes.
+	if plan.ExportSSHPrivateKey.ValueBool() {
+		sshPrivateKey := state.SSHPrivateKey
+		if !state.ExportSSHPrivateKey.ValueBool() || !plan.EncryptSSHPrivateKey.Equal(state.EncryptSSHPrivateKey) {
+			sshPrivateKey = types.StringUnknown()
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("ssh_private_key"), sshPrivateKey)...)
 	}
 
-	publicKey, err := key.GetArmoredPublicKey()
-	if err != nil {
-		resp.Diagnostics.AddError("GPG key generation failed", fmt.Sprintf("GetArmoredPublicKey failed with error: %s", err))
+	if !plan.EscrowRecipients.IsNull() {
+		escrowedPrivateKey := state.EscrowedPrivateKey
+		if !plan.EscrowRecipients.Equal(state.EscrowRecipients) {
+			escrowedPrivateKey = types.StringUnknown()
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("escrowed_private_key"), escrowedPrivateKey)...)
+	}
+
+	if !plan.Comment.Equal(state.Comment) {
+		privateKey, publicKey := types.StringUnknown(), types.StringUnknown()
+		if !plan.Comment.IsUnknown() && !plan.PrivateKeyHex.IsUnknown() {
+			var diags diag.Diagnostics
+			privateKey, publicKey, diags = armoredKeysFromHex(plan.PrivateKeyHex.ValueString(), plan.Comment.ValueString())
+			resp.Diagnostics.Append(diags...)
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("private_key"), privateKey)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("public_key"), publicKey)...)
+	}
+}
+
+func (g KeyPairResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data keyPairModelV1
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	publicKeyHex, err := key.GetPublicKey()
-	if err != nil {
-		resp.Diagnostics.AddError("GPG key pair generation failed", fmt.Sprintf("GetPublicKey failed with error: %s", err))
+	// The write-only passphrase is only available in the configuration.
+	passphrase := data.Passphrase
+	if passphrase.IsNull() {
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("passphrase_wo"), &passphrase)...)
+	}
+
+	var creationTime time.Time
+	if !data.CreationTime.IsNull() {
+		var err error
+		creationTime, err = parseCreationTime(data.CreationTime.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("creation_time"), "GPG key pair generation failed", err.Error())
+			return
+		}
+	}
+
+	settings := keyPairGeneration{
+		Identities:           data.Identities,
+		Passphrase:           passphrase.ValueString(),
+		Profile:              data.Profile,
+		DeterministicSeed:    data.DeterministicSeed.ValueString(),
+		CreationTime:         creationTime,
+		Expiry:               data.Expiry,
+		S2K:                  data.S2K,
+		Preferences:          data.Preferences,
+		Notations:            data.Notations,
+		PreferredKeyserver:   data.PreferredKeyserver.ValueString(),
+		PolicyURI:            data.PolicyURI.ValueString(),
+		AuthenticationSubkey: data.AuthenticationSubkey.ValueBool(),
+		ExportSSHPrivateKey:  data.ExportSSHPrivateKey.ValueBool(),
+		EncryptSSHPrivateKey: encryptSSHPrivateKey(data.EncryptSSHPrivateKey),
+		Comment:              data.Comment,
+	}
+	keys, diags := generateKeyPair(ctx, g.defaults, &settings)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	data.Id = types.StringValue(key.GetHexKeyID())
-	data.Fingerprint = types.StringValue(key.GetFingerprint())
-	data.PrivateKey = types.StringValue(privateKey)
-	data.PrivateKeyHex = types.StringValue(hex.EncodeToString(privateKeyHex))
-	data.PublicKey = types.StringValue(publicKey)
-	data.PublicKeyHex = types.StringValue(hex.EncodeToString(publicKeyHex))
+	data.Profile = settings.Profile
+	data.Expiry = settings.Expiry
+	data.S2K = settings.S2K
+	data.Comment = settings.Comment
+	data.Id = keys.Id
+	data.CreatedAt = keys.CreatedAt
+	data.Fingerprint = keys.Fingerprint
+	data.Keygrips = keys.Keygrips
+	data.OpenpgpkeyRecords = keys.OpenpgpkeyRecords
+	data.PrivateKey = keys.PrivateKey
+	data.PrivateKeyHex = keys.PrivateKeyHex
+	data.PrivateKeyBase64 = keys.PrivateKeyBase64
+	data.PrivateSubkeysOnly = keys.PrivateSubkeysOnly
+	data.PublicKey = keys.PublicKey
+	data.PublicKeyHex = keys.PublicKeyHex
+	data.PublicKeyBase64 = keys.PublicKeyBase64
+	data.Paperkey = keys.Paperkey
+	data.SSHPublicKey = keys.SSHPublicKey
+	data.SSHPrivateKey = keys.SSHPrivateKey
+
+	data.WKDBase = g.resolveWKDBase(data.WKDBase)
+	data.WKDEntries, diags = wkdEntriesFromHex(ctx, data.PublicKeyHex.ValueString(), data.WKDBase.ValueString())
+	resp.Diagnostics.Append(diags...)
+
+	data.EscrowedPrivateKey, diags = escrowPrivateKey(ctx, data.EscrowRecipients, data.PrivateKey.ValueString(), data.Profile.ValueString(), data.Comment.ValueString())
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -225,7 +798,8 @@ func (g KeyPairResource) Read(ctx context.Context, req resource.ReadRequest, res
 	// Nothing to do here.
 }
 
-// Update ensures the plan value is copied to the state to complete the update.
+// Update ensures the plan value is copied to the state to complete the update. The keys are re-armored if the
+// comment changed and the escrowed private key is encrypted again if the escrow recipients changed.
 func (g KeyPairResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	var model keyPairModelV1
 
@@ -235,23 +809,747 @@ func (g KeyPairResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
+	if model.PrivateKey.IsUnknown() || model.PublicKey.IsUnknown() {
+		privateKey, publicKey, diags := armoredKeysFromHex(model.PrivateKeyHex.ValueString(), model.Comment.ValueString())
+		resp.Diagnostics.Append(diags...)
+		model.PrivateKey = privateKey
+		model.PublicKey = publicKey
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if model.SSHPrivateKey.IsUnknown() && !model.ExportSSHPrivateKey.ValueBool() {
+		model.SSHPrivateKey = types.StringNull()
+	}
+	if model.SSHPrivateKey.IsUnknown() {
+		privateKeyHex, err := hex.DecodeString(model.PrivateKeyHex.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("GPG key pair update failed", fmt.Sprintf("DecodeString failed with error: %s", err))
+			return
+		}
+
+		key, err := gpgcrypto.NewKey(privateKeyHex)
+		if err != nil {
+			resp.Diagnostics.AddError("GPG key pair update failed", fmt.Sprintf("NewKey failed with error: %s", err))
+			return
+		}
+
+		// The write-only passphrase is only available in the configuration.
+		passphrase := model.Passphrase
+		if passphrase.IsNull() {
+			resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("passphrase_wo"), &passphrase)...)
+
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+
+		unlocked, err := key.Unlock([]byte(passphrase.ValueString()))
+		if err != nil {
+			resp.Diagnostics.AddError("GPG key pair update failed", fmt.Sprintf("Unlock failed with error: %s", err))
+			return
+		}
+		defer unlocked.ClearPrivateParams()
+
+		sshPrivateKey, diags := sshPrivateKeyFromEntity(unlocked.GetEntity(), encryptSSHPrivateKey(model.EncryptSSHPrivateKey), passphrase.ValueString())
+		resp.Diagnostics.Append(diags...)
+		model.SSHPrivateKey = sshPrivateKey
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if model.WKDEntries.IsUnknown() {
+		entries, diags := wkdEntriesFromHex(ctx, model.PublicKeyHex.ValueString(), model.WKDBase.ValueString())
+		resp.Diagnostics.Append(diags...)
+		model.WKDEntries = entries
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if model.EscrowedPrivateKey.IsUnknown() {
+		escrowedPrivateKey, diags := escrowPrivateKey(ctx, model.EscrowRecipients, model.PrivateKey.ValueString(), model.Profile.ValueString(), model.Comment.ValueString())
+		resp.Diagnostics.Append(diags...)
+		model.EscrowedPrivateKey = escrowedPrivateKey
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
 }
 
+// resolveWKDBase returns the base URL of the Web Key Directory, which defaults to the provider's default_wkd_base.
+func (g KeyPairResource) resolveWKDBase(base types.String) types.String {
+	var defaults GpgProviderModel
+	if g.defaults != nil {
+		defaults = *g.defaults
+	}
+	return resolveString(base, defaults.DefaultWKDBase, "")
+}
+
 func (g KeyPairResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	// Nothing to do here.
 }
 
 type keyPairModelV1 struct {
-	Id            types.String      `tfsdk:"id"`
-	Identities    []identityModelV1 `tfsdk:"identities"`
-	Passphrase    types.String      `tfsdk:"passphrase"`
-	Fingerprint   types.String      `tfsdk:"fingerprint"`
-	PrivateKey    types.String      `tfsdk:"private_key"`
-	PrivateKeyHex types.String      `tfsdk:"private_key_hex"`
-	PublicKey     types.String      `tfsdk:"public_key"`
-	PublicKeyHex  types.String      `tfsdk:"public_key_hex"`
+	Id                   types.String      `tfsdk:"id"`
+	Identities           []identityModelV1 `tfsdk:"identities"`
+	Passphrase           types.String      `tfsdk:"passphrase"`
+	PassphraseWO         types.String      `tfsdk:"passphrase_wo"`
+	PassphraseVersion    types.Int64       `tfsdk:"passphrase_version"`
+	Profile              types.String      `tfsdk:"profile"`
+	DeterministicSeed    types.String      `tfsdk:"deterministic_seed"`
+	CreationTime         types.String      `tfsdk:"creation_time"`
+	Expiry               types.String      `tfsdk:"expiry"`
+	S2K                  types.Object      `tfsdk:"s2k"`
+	Preferences          types.Object      `tfsdk:"preferences"`
+	Notations            []notationModelV1 `tfsdk:"notations"`
+	PreferredKeyserver   types.String      `tfsdk:"preferred_keyserver"`
+	PolicyURI            types.String      `tfsdk:"policy_uri"`
+	AuthenticationSubkey types.Bool        `tfsdk:"authentication_subkey"`
+	ExportSSHPrivateKey  types.Bool        `tfsdk:"export_ssh_private_key"`
+	EncryptSSHPrivateKey types.Bool        `tfsdk:"encrypt_ssh_private_key"`
+	Comment              types.String      `tfsdk:"comment"`
+	EscrowRecipients     types.List        `tfsdk:"escrow_recipients"`
+	CreatedAt            types.String      `tfsdk:"created_at"`
+	EscrowedPrivateKey   types.String      `tfsdk:"escrowed_private_key"`
+	Fingerprint          types.String      `tfsdk:"fingerprint"`
+	Keygrips             types.List        `tfsdk:"keygrips"`
+	OpenpgpkeyRecords    types.List        `tfsdk:"openpgpkey_records"`
+	WKDBase              types.String      `tfsdk:"wkd_base"`
+	WKDEntries           types.List        `tfsdk:"wkd_entries"`
+	PrivateKey           types.String      `tfsdk:"private_key"`
+	PrivateKeyHex        types.String      `tfsdk:"private_key_hex"`
+	PrivateKeyBase64     types.String      `tfsdk:"private_key_base64"`
+	PrivateSubkeysOnly   types.String      `tfsdk:"private_subkeys_only"`
+	PublicKey            types.String      `tfsdk:"public_key"`
+	PublicKeyHex         types.String      `tfsdk:"public_key_hex"`
+	PublicKeyBase64      types.String      `tfsdk:"public_key_base64"`
+	Paperkey             types.String      `tfsdk:"paperkey"`
+	SSHPublicKey         types.String      `tfsdk:"ssh_public_key"`
+	SSHPrivateKey        types.String      `tfsdk:"ssh_private_key"`
 }
+
+// validateKeyPairSettings validates the key generation settings shared by the resource and the ephemeral resource.
+func validateKeyPairSettings(ctx context.Context, identities []identityModelV1, profile types.String, expiry types.String, s2k types.Object) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if len(identities) == 0 {
+		diags.AddAttributeError(
+			path.Root("identities"),
+			"GPG v4 key pairs need at least one identity",
+			"GPG v4 key pairs need at least one identity.",
+		)
+		return diags
+	}
+
+	if !profile.IsNull() && !profile.IsUnknown() {
+		if _, err := profileByName(profile.ValueString()); err != nil {
+			diags.AddAttributeError(path.Root("profile"), "Invalid profile", err.Error())
+		}
+	}
+	if !expiry.IsNull() && !expiry.IsUnknown() {
+		if _, err := parseExpiry(expiry.ValueString()); err != nil {
+			diags.AddAttributeError(path.Root("expiry"), "Invalid expiry", err.Error())
+		}
+	}
+	if !s2k.IsNull() && !s2k.IsUnknown() {
+		var s2kModel s2kModelV1
+		diags.Append(s2k.As(ctx, &s2kModel, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return diags
+		}
+		if err := s2kModel.validate(); err != nil {
+			diags.AddAttributeError(path.Root("s2k"), "Invalid S2K settings", err.Error())
+		}
+	}
+	return diags
+}
+
+// validateAuthenticationSubkey rejects an authentication subkey if the profile, or the provider's default_profile,
+// generates keys that have no SSH representation.
+func validateAuthenticationSubkey(providerDefaults *GpgProviderModel, profile types.String, authenticationSubkey types.Bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !authenticationSubkey.ValueBool() || profile.IsUnknown() {
+		return diags
+	}
+
+	var defaults GpgProviderModel
+	if providerDefaults != nil {
+		defaults = *providerDefaults
+	}
+
+	keyProfile, err := profileByName(resolveString(profile, defaults.DefaultProfile, defaultProfileName).ValueString())
+	if err != nil {
+		// An invalid profile is reported by validateKeyPairSettings or by the provider.
+		return diags
+	}
+	if err := sshKeyAlgorithmSupported(keyProfile.KeyGenerationConfig(constants.HighSecurity)); err != nil {
+		diags.AddAttributeError(path.Root("authentication_subkey"), "Invalid authentication subkey", fmt.Sprintf("The authentication subkey cannot be used with SSH: %s.", err))
+	}
+	return diags
+}
+
+// keyPairGeneration holds the settings for generating a key pair. The profile, expiry, S2K settings and comment are
+// resolved against the provider defaults by generateKeyPair.
+type keyPairGeneration struct {
+	Identities           []identityModelV1
+	Passphrase           string
+	Profile              types.String
+	DeterministicSeed    string
+	CreationTime         time.Time
+	Expiry               types.String
+	S2K                  types.Object
+	Preferences          types.Object
+	Notations            []notationModelV1
+	PreferredKeyserver   string
+	PolicyURI            string
+	AuthenticationSubkey bool
+	ExportSSHPrivateKey  bool
+	EncryptSSHPrivateKey bool
+	Comment              types.String
+}
+
+// keyPairKeys holds the keys of a generated key pair in all output formats.
+type keyPairKeys struct {
+	Id                 types.String
+	CreatedAt          types.String
+	Fingerprint        types.String
+	Keygrips           types.List
+	OpenpgpkeyRecords  types.List
+	PrivateKey         types.String
+	PrivateKeyHex      types.String
+	PrivateKeyBase64   types.String
+	PrivateSubkeysOnly types.String
+	PublicKey          types.String
+	PublicKeyHex       types.String
+	PublicKeyBase64    types.String
+	Paperkey           types.String
+	SSHPublicKey       types.String
+	SSHPrivateKey      types.String
+}
+
+// generateKeyPair generates a key pair with the settings and locks it with their passphrase. The key is created at the
+// current time if the creation time of the settings is zero. It is shared by the resource and the ephemeral resource.
+func generateKeyPair(ctx context.Context, providerDefaults *GpgProviderModel, settings *keyPairGeneration) (keyPairKeys, diag.Diagnostics) {
+	var keys keyPairKeys
+	var diags diag.Diagnostics
+
+	var defaults GpgProviderModel
+	if providerDefaults != nil {
+		defaults = *providerDefaults
+	}
+
+	settings.Profile = resolveString(settings.Profile, defaults.DefaultProfile, defaultProfileName)
+	settings.Expiry = resolveString(settings.Expiry, defaults.DefaultExpiry, defaultExpiry)
+	settings.Comment = resolveString(settings.Comment, defaults.DefaultComment, "")
+
+	keyProfile, err := profileByName(settings.Profile.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("profile"), "GPG key pair generation failed", err.Error())
+		return keys, diags
+	}
+
+	lifetime, err := parseExpiry(settings.Expiry.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("expiry"), "GPG key pair generation failed", err.Error())
+		return keys, diags
+	}
+
+	var s2kModel *s2kModelV1
+	if !settings.S2K.IsNull() && !settings.S2K.IsUnknown() {
+		s2kModel = &s2kModelV1{}
+		diags.Append(settings.S2K.As(ctx, s2kModel, basetypes.ObjectAsOptions{UnhandledUnknownAsEmpty: true})...)
+	}
+	s2kModel = resolveS2K(s2kModel, defaults.DefaultS2K, keyProfile)
+	keyProfile.S2kKeyEncryption = s2kModel.config()
+
+	selfSignatures := selfSignatureSettings{
+		notations:          settings.Notations,
+		preferredKeyserver: settings.PreferredKeyserver,
+		policyURI:          settings.PolicyURI,
+	}
+	if !settings.Preferences.IsNull() && !settings.Preferences.IsUnknown() {
+		var preferencesModel preferencesModelV1
+		diags.Append(settings.Preferences.As(ctx, &preferencesModel, basetypes.ObjectAsOptions{})...)
+		if !diags.HasError() {
+			var preferencesDiags diag.Diagnostics
+			selfSignatures.preferences, preferencesDiags = preferencesModel.resolve(ctx)
+			diags.Append(preferencesDiags...)
+		}
+	}
+
+	var s2kDiags diag.Diagnostics
+	settings.S2K, s2kDiags = types.ObjectValueFrom(ctx, s2kAttrTypes, s2kModel)
+	diags.Append(s2kDiags...)
+
+	if diags.HasError() {
+		return keys, diags
+	}
+
+	var pgp = gpgcrypto.PGPWithProfile(keyProfile)
+
+	// A deterministic key reads all random values from the seed and uses a fixed creation time.
+	var random io.Reader
+	var key *gpgcrypto.Key
+	creationTime := settings.CreationTime
+	if settings.DeterministicSeed != "" {
+		if creationTime.IsZero() {
+			creationTime = deterministicCreationTime
+		}
+		random = deterministicRand([]byte(settings.DeterministicSeed), []byte("key generation"), nil)
+		key, err = generateDeterministicKey(keyProfile, settings.Identities, creationTime, lifetime, random)
+		if err != nil {
+			diags.AddAttributeError(path.Root("deterministic_seed"), "GPG key pair generation failed", fmt.Sprintf("generateDeterministicKey failed with error: %s", err))
+			return keys, diags
+		}
+	} else {
+		builder := pgp.KeyGeneration().Lifetime(lifetime)
+		if !creationTime.IsZero() {
+			builder = builder.GenerationTime(creationTime.Unix())
+		}
+		for _, identity := range settings.Identities {
+			builder = builder.AddUserId(identity.Name.ValueString(), identity.Email.ValueString())
+		}
+
+		key, err = builder.New().GenerateKeyWithSecurity(constants.HighSecurity)
+
+		if err != nil {
+			diags.AddError("GPG key pair generation failed", fmt.Sprintf("GenerateKeyWithSecurity failed with error: %s", err))
+			return keys, diags
+		}
+	}
+	defer key.ClearPrivateParams()
+
+	config := keyProfile.KeyGenerationConfig(constants.HighSecurity)
+	config.KeyLifetimeSecs = uint32(lifetime)
+	if !creationTime.IsZero() {
+		config.Time = func() time.Time { return creationTime }
+	}
+	if random != nil {
+		config.Rand = random
+	}
+
+	if !selfSignatures.empty() {
+		if err := selfSignatures.apply(key.GetEntity(), config); err != nil {
+			diags.AddError("GPG key pair generation failed", fmt.Sprintf("Updating the self-signatures failed with error: %s", err))
+			return keys, diags
+		}
+	}
+
+	if settings.AuthenticationSubkey {
+		if err := addAuthenticationSubkey(key.GetEntity(), config); err != nil {
+			diags.AddError("GPG key pair generation failed", fmt.Sprintf("addAuthenticationSubkey failed with error: %s", err))
+			return keys, diags
+		}
+	}
+
+	keys.SSHPrivateKey = types.StringNull()
+	if settings.ExportSSHPrivateKey {
+		sshPrivateKey, sshDiags := sshPrivateKeyFromEntity(key.GetEntity(), settings.EncryptSSHPrivateKey, settings.Passphrase)
+		diags.Append(sshDiags...)
+		keys.SSHPrivateKey = sshPrivateKey
+
+		if diags.HasError() {
+			return keys, diags
+		}
+	}
+
+	if random != nil {
+		key, err = lockDeterministicKey(keyProfile, key, []byte(settings.Passphrase), random)
+	} else {
+		key, err = pgp.LockKey(key, []byte(settings.Passphrase))
+	}
+	if err != nil {
+		diags.AddError("GPG key pair generation failed", fmt.Sprintf("LockKey failed with error: %s", err))
+		return keys, diags
+	}
+
+	privateKey, err := key.ArmorWithCustomHeaders(settings.Comment.ValueString(), "")
+	if err != nil {
+		diags.AddError("GPG key pair generation failed", fmt.Sprintf("Armor failed with error: %s", err))
+		return keys, diags
+	}
+
+	privateKeyHex, err := key.Serialize()
+	if err != nil {
+		diags.AddError("GPG key pair generation failed", fmt.Sprintf("Serialize failed with error: %s", err))
+		return keys, diags
+	}
+
+	publicKey, err := key.GetArmoredPublicKeyWithCustomHeaders(settings.Comment.ValueString(), "")
+	if err != nil {
+		diags.AddError("GPG key generation failed", fmt.Sprintf("GetArmoredPublicKey failed with error: %s", err))
+		return keys, diags
+	}
+
+	publicKeyHex, err := key.GetPublicKey()
+	if err != nil {
+		diags.AddError("GPG key pair generation failed", fmt.Sprintf("GetPublicKey failed with error: %s", err))
+		return keys, diags
+	}
+
+	keys.Id = types.StringValue(key.GetHexKeyID())
+	keys.CreatedAt = types.StringValue(key.GetEntity().PrimaryKey.CreationTime.UTC().Format(time.RFC3339))
+	keys.Fingerprint = types.StringValue(key.GetFingerprint())
+	keys.PrivateKey = types.StringValue(privateKey)
+	keys.PrivateKeyHex = types.StringValue(hex.EncodeToString(privateKeyHex))
+	keys.PublicKey = types.StringValue(publicKey)
+	keys.PublicKeyHex = types.StringValue(hex.EncodeToString(publicKeyHex))
+	keys.PublicKeyBase64 = types.StringValue(base64.StdEncoding.EncodeToString(publicKeyHex))
+	keys.PrivateKeyBase64 = types.StringValue(base64.StdEncoding.EncodeToString(privateKeyHex))
+
+	sshKey, sshDiags := sshPublicKeyFromHex(keys.PublicKeyHex.ValueString())
+	diags.Append(sshDiags...)
+	keys.SSHPublicKey = sshKey
+
+	grips, keygripDiags := keygripsFromHex(ctx, keys.PublicKeyHex.ValueString())
+	diags.Append(keygripDiags...)
+	keys.Keygrips = grips
+
+	records, recordDiags := openpgpkeyRecordsFromHex(ctx, keys.PublicKeyHex.ValueString())
+	diags.Append(recordDiags...)
+	keys.OpenpgpkeyRecords = records
+
+	paperkey, paperkeyDiags := paperkeyFromHex(keys.PrivateKeyHex.ValueString())
+	diags.Append(paperkeyDiags...)
+	keys.Paperkey = paperkey
+
+	subkeysOnly, subkeysOnlyDiags := privateSubkeysOnlyFromHex(keys.PrivateKeyHex.ValueString(), settings.Comment.ValueString())
+	diags.Append(subkeysOnlyDiags...)
+	keys.PrivateSubkeysOnly = subkeysOnly
+
+	return keys, diags
+}
+
+// parseCreationTime parses a creation time in RFC 3339 format, which must be in the past.
+func parseCreationTime(creationTime string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, creationTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if t.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("creation time %s is in the future", creationTime)
+	}
+	return t, nil
+}
+
+// createdAtFromHex returns the creation time of the primary key of a public key in hex format.
+func createdAtFromHex(publicKeyHex string) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	publicKey, err := hex.DecodeString(publicKeyHex)
+	if err != nil {
+		diags.AddError("GPG key pair creation time failed", fmt.Sprintf("DecodeString failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+
+	key, err := gpgcrypto.NewKey(publicKey)
+	if err != nil {
+		diags.AddError("GPG key pair creation time failed", fmt.Sprintf("NewKey failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+	return types.StringValue(key.GetEntity().PrimaryKey.CreationTime.UTC().Format(time.RFC3339)), diags
+}
+
+// base64FromHex converts a key in hex format to base64 format, the encoding of binary keys expected by other providers.
+func base64FromHex(keyHex string) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	key, err := hex.DecodeString(keyHex)
+	if err != nil {
+		diags.AddError("GPG key encoding failed", fmt.Sprintf("DecodeString failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+
+	return types.StringValue(base64.StdEncoding.EncodeToString(key)), diags
+}
+
+// keygripsFromHex returns the keygrips of the primary key and the subkeys of a public key in hex format, or null if
+// an algorithm is not supported.
+func keygripsFromHex(ctx context.Context, publicKeyHex string) (types.List, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	publicKey, err := hex.DecodeString(publicKeyHex)
+	if err != nil {
+		diags.AddError("GPG keygrip computation failed", fmt.Sprintf("DecodeString failed with error: %s", err))
+		return types.ListNull(types.StringType), diags
+	}
+
+	key, err := gpgcrypto.NewKey(publicKey)
+	if err != nil {
+		diags.AddError("GPG keygrip computation failed", fmt.Sprintf("NewKey failed with error: %s", err))
+		return types.ListNull(types.StringType), diags
+	}
+
+	entity := key.GetEntity()
+	subkeys := make([]*packet.PublicKey, 0, len(entity.Subkeys))
+	for _, subkey := range entity.Subkeys {
+		subkeys = append(subkeys, subkey.PublicKey)
+	}
+	grips, err := keygrips(entity.PrimaryKey, subkeys)
+	if err != nil {
+		return types.ListNull(types.StringType), diags
+	}
+
+	list, listDiags := types.ListValueFrom(ctx, types.StringType, grips)
+	diags.Append(listDiags...)
+	return list, diags
+}
+
+// openpgpkeyRecordsFromHex returns the OPENPGPKEY records of the email addresses of a public key in hex format, sorted
+// by email.
+func openpgpkeyRecordsFromHex(ctx context.Context, publicKeyHex string) (types.List, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	recordsType := types.ObjectType{AttrTypes: openpgpkeyRecordAttrTypes}
+
+	publicKey, err := hex.DecodeString(publicKeyHex)
+	if err != nil {
+		diags.AddError("GPG OPENPGPKEY record generation failed", fmt.Sprintf("DecodeString failed with error: %s", err))
+		return types.ListNull(recordsType), diags
+	}
+
+	key, err := gpgcrypto.NewKey(publicKey)
+	if err != nil {
+		diags.AddError("GPG OPENPGPKEY record generation failed", fmt.Sprintf("NewKey failed with error: %s", err))
+		return types.ListNull(recordsType), diags
+	}
+
+	entity := key.GetEntity()
+	emails := publishableEmails(entity)
+
+	records := make([]openpgpkeyRecordModelV1, 0, len(emails))
+	for _, email := range emails {
+		record, err := openpgpkeyRecord(entity, email)
+		if err != nil {
+			diags.AddError("GPG OPENPGPKEY record generation failed", err.Error())
+			return types.ListNull(recordsType), diags
+		}
+		records = append(records, *record)
+	}
+
+	list, listDiags := types.ListValueFrom(ctx, recordsType, records)
+	diags.Append(listDiags...)
+	return list, diags
+}
+
+// armoredKeysFromHex returns a private key in hex format as armored private and public key with the comment.
+func armoredKeysFromHex(privateKeyHex string, comment string) (types.String, types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	privateKeyBytes, err := hex.DecodeString(privateKeyHex)
+	if err != nil {
+		diags.AddError("GPG key pair armor failed", fmt.Sprintf("DecodeString failed with error: %s", err))
+		return types.StringNull(), types.StringNull(), diags
+	}
+
+	key, err := gpgcrypto.NewKey(privateKeyBytes)
+	if err != nil {
+		diags.AddError("GPG key pair armor failed", fmt.Sprintf("NewKey failed with error: %s", err))
+		return types.StringNull(), types.StringNull(), diags
+	}
+
+	privateKey, err := key.ArmorWithCustomHeaders(comment, "")
+	if err != nil {
+		diags.AddError("GPG key pair armor failed", fmt.Sprintf("Armor failed with error: %s", err))
+		return types.StringNull(), types.StringNull(), diags
+	}
+
+	publicKey, err := key.GetArmoredPublicKeyWithCustomHeaders(comment, "")
+	if err != nil {
+		diags.AddError("GPG key pair armor failed", fmt.Sprintf("GetArmoredPublicKey failed with error: %s", err))
+		return types.StringNull(), types.StringNull(), diags
+	}
+	return types.StringValue(privateKey), types.StringValue(publicKey), diags
+}
+
+// privateSubkeysOnlyFromHex returns a private key in hex format as armored private key without the secret key material
+// of the primary key.
+func privateSubkeysOnlyFromHex(privateKeyHex string, comment string) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	privateKey, err := hex.DecodeString(privateKeyHex)
+	if err != nil {
+		diags.AddError("GPG secret subkeys export failed", fmt.Sprintf("DecodeString failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+
+	entities, err := openpgp.ReadKeyRing(bytes.NewReader(privateKey))
+	if err != nil {
+		diags.AddError("GPG secret subkeys export failed", fmt.Sprintf("ReadKeyRing failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+	if len(entities) != 1 {
+		diags.AddError("GPG secret subkeys export failed", fmt.Sprintf("Expected exactly one key, got %d.", len(entities)))
+		return types.StringNull(), diags
+	}
+
+	serialized, err := exportSecretSubkeys(entities[0])
+	if err != nil {
+		diags.AddError("GPG secret subkeys export failed", fmt.Sprintf("exportSecretSubkeys failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+
+	subkeysOnly, err := armorPrivateKey(entities[0], serialized, comment)
+	if err != nil {
+		diags.AddError("GPG secret subkeys export failed", fmt.Sprintf("Armor failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+	return types.StringValue(subkeysOnly), diags
+}
+
+// escrowPrivateKey encrypts the armored private key to the escrow recipients with the settings of the profile. It
+// returns null if there are no escrow recipients.
+func escrowPrivateKey(ctx context.Context, escrowRecipients types.List, privateKey string, profileName string, comment string) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if escrowRecipients.IsNull() {
+		return types.StringNull(), diags
+	}
+
+	var publicKeys []string
+	diags.Append(escrowRecipients.ElementsAs(ctx, &publicKeys, false)...)
+
+	if diags.HasError() {
+		return types.StringNull(), diags
+	}
+
+	keyProfile, err := profileByName(profileName)
+	if err != nil {
+		diags.AddError("GPG key escrow failed", err.Error())
+		return types.StringNull(), diags
+	}
+
+	recipients, err := gpgcrypto.NewKeyRing(nil)
+	if err != nil {
+		diags.AddError("GPG key escrow failed", fmt.Sprintf("NewKeyRing failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+	for i, publicKey := range publicKeys {
+		key, err := gpgcrypto.NewKeyFromArmored(publicKey)
+		if err != nil {
+			diags.AddAttributeError(path.Root("escrow_recipients").AtListIndex(i), "GPG key escrow failed", fmt.Sprintf("NewKeyFromArmored failed with error: %s", err))
+			return types.StringNull(), diags
+		}
+		if err := recipients.AddKey(key); err != nil {
+			diags.AddAttributeError(path.Root("escrow_recipients").AtListIndex(i), "GPG key escrow failed", fmt.Sprintf("AddKey failed with error: %s", err))
+			return types.StringNull(), diags
+		}
+	}
+	if recipients.CountEntities() == 0 {
+		diags.AddAttributeError(path.Root("escrow_recipients"), "GPG key escrow failed", "At least one escrow recipient is required.")
+		return types.StringNull(), diags
+	}
+
+	handle, err := gpgcrypto.PGPWithProfile(keyProfile).Encryption().Recipients(recipients).New()
+	if err != nil {
+		diags.AddError("GPG key escrow failed", fmt.Sprintf("New failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+
+	message, err := handle.Encrypt([]byte(privateKey))
+	if err != nil {
+		diags.AddError("GPG key escrow failed", fmt.Sprintf("Encrypt failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+
+	escrowedPrivateKey, err := message.ArmorWithCustomHeaders(comment, "")
+	if err != nil {
+		diags.AddError("GPG key escrow failed", fmt.Sprintf("Armor failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+	return types.StringValue(escrowedPrivateKey), diags
+}
+
+// paperkeyFromHex returns the paperkey text of a private key in hex format, or null if the key version is not
+// supported by paperkey.
+func paperkeyFromHex(privateKeyHex string) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	privateKey, err := hex.DecodeString(privateKeyHex)
+	if err != nil {
+		diags.AddError("GPG paperkey conversion failed", fmt.Sprintf("DecodeString failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+
+	paperkey, err := toPaperkey(privateKey)
+	if errors.Is(err, errPaperkeyVersion) {
+		return types.StringNull(), diags
+	}
+	if err != nil {
+		diags.AddError("GPG paperkey conversion failed", fmt.Sprintf("toPaperkey failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+	return types.StringValue(paperkey), diags
+}
+
+// encryptSSHPrivateKey returns whether the SSH private key is encrypted with the passphrase, which is the default.
+func encryptSSHPrivateKey(encrypt types.Bool) bool {
+	return encrypt.IsNull() || encrypt.ValueBool()
+}
+
+// sshPrivateKeyFromEntity returns the OpenSSH private key of the authentication key of an unlocked entity, or null if
+// it has no authentication key. The SSH key is encrypted with the passphrase if encrypt is set.
+func sshPrivateKeyFromEntity(entity *openpgp.Entity, encrypt bool, passphrase string) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	_, privateKey, err := authenticationKey(entity)
+	if errors.Is(err, errNoAuthenticationKey) {
+		return types.StringNull(), diags
+	}
+	if err != nil {
+		diags.AddError("GPG SSH private key conversion failed", fmt.Sprintf("authenticationKey failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+
+	var sshPassphrase []byte
+	if encrypt {
+		sshPassphrase = []byte(passphrase)
+	}
+	sshKey, err := sshPrivateKey(privateKey, sshPassphrase)
+	if err != nil {
+		diags.AddError("GPG SSH private key conversion failed", fmt.Sprintf("sshPrivateKey failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+	return types.StringValue(sshKey), diags
+}
+
+// sshPublicKeyFromHex returns the SSH public key of a public key in hex format, or null if it has no authentication
+// key.
+func sshPublicKeyFromHex(publicKeyHex string) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	publicKey, err := hex.DecodeString(publicKeyHex)
+	if err != nil {
+		diags.AddError("GPG SSH public key conversion failed", fmt.Sprintf("DecodeString failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+
+	key, err := gpgcrypto.NewKey(publicKey)
+	if err != nil {
+		diags.AddError("GPG SSH public key conversion failed", fmt.Sprintf("NewKey failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+
+	sshKey, err := sshAuthorizedKey(key.GetEntity())
+	if errors.Is(err, errNoAuthenticationKey) {
+		return types.StringNull(), diags
+	}
+	if err != nil {
+		diags.AddError("GPG SSH public key conversion failed", fmt.Sprintf("sshAuthorizedKey failed with error: %s", err))
+		return types.StringNull(), diags
+	}
+	return types.StringValue(sshKey), diags
+}
+
 type identityModelV1 struct {
 	Name  types.String `tfsdk:"name"`
 	Email types.String `tfsdk:"email"`
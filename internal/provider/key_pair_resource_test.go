@@ -1,14 +1,25 @@
 package provider
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"github.com/ProtonMail/go-crypto/openpgp/packet"
 	"github.com/ProtonMail/gopenpgp/v3/crypto"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"golang.org/x/crypto/ssh"
+	"regexp"
+	"strings"
 	"testing"
-	"unsafe"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-testing/compare"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
 )
 
 func TestAccKeyPairResource(t *testing.T) {
@@ -21,6 +32,11 @@ func TestAccKeyPairResource(t *testing.T) {
 				Config: testAccKeyPairResourceConfig("John Doe", "john.doe@example.com", "top secret"),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					testAccCheckGpgKeyPair("gpg_key_pair.test"),
+					resource.TestCheckNoResourceAttr("gpg_key_pair.test", "ssh_public_key"),
+					resource.TestMatchResourceAttr("gpg_key_pair.test", "created_at", regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`)),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "keygrips.#", "2"),
+					resource.TestMatchResourceAttr("gpg_key_pair.test", "keygrips.0", regexp.MustCompile(`^[0-9A-F]{40}$`)),
+					testAccCheckGpgKeyPairBase64("gpg_key_pair.test"),
 				),
 			},
 			// Update and Read testing
@@ -28,6 +44,7 @@ func TestAccKeyPairResource(t *testing.T) {
 				Config: testAccKeyPairResourceConfig("Jane Doe", "jane.doe@example.com", "top secret"),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					testAccCheckGpgKeyPair("gpg_key_pair.test"),
+					testAccCheckGpgKeyPairBase64("gpg_key_pair.test"),
 				),
 			},
 			// Delete testing automatically occurs in TestCase
@@ -35,7 +52,648 @@ func TestAccKeyPairResource(t *testing.T) {
 	})
 }
 
+func TestAccKeyPairResource_providerDefaults(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccKeyPairResourceProviderDefaultsConfig(`expiry = "8760h"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "profile", "rfc9580"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "expiry", "8760h"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "s2k.mode", "argon2"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "s2k.argon2_passes", "1"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "s2k.argon2_memory", "1024"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "comment", "managed by terraform"),
+					resource.TestMatchResourceAttr("gpg_key_pair.test", "public_key", regexp.MustCompile("Comment: managed by terraform")),
+					testAccCheckGpgKeyPairVersion("gpg_key_pair.test", 6),
+					resource.TestCheckNoResourceAttr("gpg_key_pair.test", "paperkey"),
+					resource.TestCheckNoResourceAttr("gpg_key_pair.test", "keygrips"),
+					testAccCheckGpgSecretSubkeys("gpg_key_pair.test", ""),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "wkd_base", "https://openpgpkey.example.com/.well-known/openpgpkey/example.com"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "wkd_entries.#", "1"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "wkd_entries.0.email", "john.doe@example.com"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "wkd_entries.0.hash", "ihyath4noz8dsckzjbuyqnh4kbup6h4i"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "wkd_entries.0.url", "https://openpgpkey.example.com/.well-known/openpgpkey/example.com/hu/ihyath4noz8dsckzjbuyqnh4kbup6h4i?l=john.doe"),
+				),
+			},
+			// Update testing, changing the comment only re-armors the keys while provider defaults do not affect
+			// existing keys
+			{
+				Config: testAccKeyPairResourceProviderDefaultsConfig(`expiry = "8760h"
+  comment = "managed by opentofu"`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_key_pair.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "comment", "managed by opentofu"),
+					resource.TestMatchResourceAttr("gpg_key_pair.test", "public_key", regexp.MustCompile("Comment: managed by opentofu")),
+					resource.TestMatchResourceAttr("gpg_key_pair.test", "private_key", regexp.MustCompile("Comment: managed by opentofu")),
+					testAccCheckGpgKeyPairVersion("gpg_key_pair.test", 6),
+				),
+			},
+			// Update testing, an own WKD base overrides the provider default
+			{
+				Config: testAccKeyPairResourceProviderDefaultsConfig(`expiry = "8760h"
+  comment = "managed by opentofu"
+  wkd_base = "https://example.com/.well-known/openpgpkey"`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_key_pair.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "wkd_base", "https://example.com/.well-known/openpgpkey"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "wkd_entries.0.url", "https://example.com/.well-known/openpgpkey/hu/ihyath4noz8dsckzjbuyqnh4kbup6h4i?l=john.doe"),
+				),
+			},
+			// Update testing, removing the WKD base falls back to the provider default
+			{
+				Config: testAccKeyPairResourceProviderDefaultsConfig(`expiry = "8760h"
+  comment = "managed by opentofu"`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_key_pair.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectKnownValue("gpg_key_pair.test", tfjsonpath.New("wkd_base"), knownvalue.StringExact("https://openpgpkey.example.com/.well-known/openpgpkey/example.com")),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "wkd_base", "https://openpgpkey.example.com/.well-known/openpgpkey/example.com"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "wkd_entries.0.url", "https://openpgpkey.example.com/.well-known/openpgpkey/example.com/hu/ihyath4noz8dsckzjbuyqnh4kbup6h4i?l=john.doe"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccKeyPairResource_wkd(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Base URLs without scheme are rejected
+			{
+				Config:      testAccKeyPairResourceWKDConfig(`wkd_base = "example.com/.well-known/openpgpkey"`),
+				ExpectError: regexp.MustCompile(`Invalid WKD base URL`),
+			},
+			// Create and Read testing
+			{
+				Config: testAccKeyPairResourceWKDConfig(""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "wkd_base", ""),
+					resource.TestCheckNoResourceAttr("gpg_key_pair.test", "wkd_entries"),
+				),
+			},
+			// Update testing, setting the base URL only computes the entries
+			{
+				Config: testAccKeyPairResourceWKDConfig(`wkd_base = "https://example.com/.well-known/openpgpkey/"`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_key_pair.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectKnownValue("gpg_key_pair.test", tfjsonpath.New("wkd_entries").AtSliceIndex(0).AtMapKey("url"), knownvalue.StringExact("https://example.com/.well-known/openpgpkey/hu/ihyath4noz8dsckzjbuyqnh4kbup6h4i?l=john.doe")),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "wkd_entries.#", "1"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "wkd_entries.0.email", "john.doe@example.com"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "wkd_entries.0.hash", "ihyath4noz8dsckzjbuyqnh4kbup6h4i"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccKeyPairResource_authenticationSubkey(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Validation testing, Ed448 keys cannot be used with SSH
+			{
+				Config:      testAccKeyPairResourceAuthenticationSubkeyConfig("rfc9580", ""),
+				ExpectError: regexp.MustCompile(`Ed448 keys are not\s+supported`),
+			},
+			// Create and Read testing
+			{
+				Config: testAccKeyPairResourceAuthenticationSubkeyConfig("gnupg", ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgKeyPair("gpg_key_pair.test"),
+					resource.TestMatchResourceAttr("gpg_key_pair.test", "ssh_public_key", regexp.MustCompile(`^ssh-ed25519 \S+ openpgp:0x[0-9A-F]{8}$`)),
+					testAccCheckOutputEqualsAttr("ssh_public_key", "gpg_key_pair.test", "ssh_public_key"),
+					resource.TestCheckNoResourceAttr("gpg_key_pair.test", "ssh_private_key"),
+				),
+			},
+			// Update testing, exporting the SSH private key keeps the key pair and encrypts the SSH key by default
+			{
+				Config: testAccKeyPairResourceAuthenticationSubkeyConfig("gnupg", "export_ssh_private_key = true"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_key_pair.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgKeyPairSSHPrivateKey("gpg_key_pair.test", true),
+				),
+			},
+			// Update testing, the SSH private key is only left unencrypted on request
+			{
+				Config: testAccKeyPairResourceAuthenticationSubkeyConfig("gnupg", `export_ssh_private_key  = true
+  encrypt_ssh_private_key = false`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_key_pair.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgKeyPairSSHPrivateKey("gpg_key_pair.test", false),
+				),
+			},
+			// Create testing with an RSA key
+			{
+				Config: testAccKeyPairResourceAuthenticationSubkeyConfig("rfc4880", "export_ssh_private_key = true"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("gpg_key_pair.test", "ssh_public_key", regexp.MustCompile(`^ssh-rsa \S+ openpgp:0x[0-9A-F]{8}$`)),
+					testAccCheckOutputEqualsAttr("ssh_public_key", "gpg_key_pair.test", "ssh_public_key"),
+					testAccCheckGpgKeyPairSSHPrivateKey("gpg_key_pair.test", true),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccKeyPairResource_writeOnlyPassphrase(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_11_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccKeyPairResourceWriteOnlyConfig("top secret", 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgKeyPairWithPassphrase("gpg_key_pair.test", "top secret"),
+					resource.TestCheckNoResourceAttr("gpg_key_pair.test", "passphrase"),
+					resource.TestCheckNoResourceAttr("gpg_key_pair.test", "passphrase_wo"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "passphrase_version", "1"),
+				),
+			},
+			// Changing only the write-only passphrase is not detected
+			{
+				Config: testAccKeyPairResourceWriteOnlyConfig("even more secret", 1),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+			// Changing the version generates a new key locked with the new passphrase
+			{
+				Config: testAccKeyPairResourceWriteOnlyConfig("even more secret", 2),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_key_pair.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgKeyPairWithPassphrase("gpg_key_pair.test", "even more secret"),
+					resource.TestCheckNoResourceAttr("gpg_key_pair.test", "passphrase_wo"),
+				),
+			},
+			// Switching to passphrase generates a new key locked with it
+			{
+				Config: testAccKeyPairResourceConfig("John Doe", "john.doe@example.com", "top secret"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_key_pair.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgKeyPairWithPassphrase("gpg_key_pair.test", "top secret"),
+				),
+			},
+			// Changing the passphrase generates a new key locked with the new passphrase
+			{
+				Config: testAccKeyPairResourceConfig("John Doe", "john.doe@example.com", "even more secret"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_key_pair.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgKeyPairWithPassphrase("gpg_key_pair.test", "even more secret"),
+				),
+			},
+			// Switching to passphrase_wo generates a new key locked with it
+			{
+				Config: testAccKeyPairResourceWriteOnlyConfig("top secret", 1),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_key_pair.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgKeyPairWithPassphrase("gpg_key_pair.test", "top secret"),
+					resource.TestCheckNoResourceAttr("gpg_key_pair.test", "passphrase"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccKeyPairResource_deterministicSeed(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccKeyPairResourceDeterministicConfig("gnupg"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.CompareValuePairs("gpg_key_pair.test", tfjsonpath.New("fingerprint"), "gpg_key_pair.same", tfjsonpath.New("fingerprint"), compare.ValuesSame()),
+					statecheck.CompareValuePairs("gpg_key_pair.test", tfjsonpath.New("private_key"), "gpg_key_pair.same", tfjsonpath.New("private_key"), compare.ValuesSame()),
+					statecheck.CompareValuePairs("gpg_key_pair.test", tfjsonpath.New("fingerprint"), "gpg_key_pair.other", tfjsonpath.New("fingerprint"), compare.ValuesDiffer()),
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgKeyPair("gpg_key_pair.test"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "fingerprint", "13b291fe449cffd8f49ad1282f3fd465ee1215c7"),
+				),
+			},
+			// RSA keys cannot be derived from a seed
+			{
+				Config:      testAccKeyPairResourceDeterministicConfig("rfc4880"),
+				ExpectError: regexp.MustCompile(`deterministic RSA keys are not\s+supported`),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccKeyPairResource_creationTime(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Creation times in the future are rejected
+			{
+				Config:      testAccKeyPairResourceCreationTimeConfig("2999-01-01T00:00:00Z"),
+				ExpectError: regexp.MustCompile(`creation time 2999-01-01T00:00:00Z is in the future`),
+			},
+			// Create and Read testing
+			{
+				Config: testAccKeyPairResourceCreationTimeConfig("2023-05-01T14:00:00+02:00"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "created_at", "2023-05-01T12:00:00Z"),
+					testAccCheckGpgKeyPairCreationTime("gpg_key_pair.test", time.Date(2023, time.May, 1, 12, 0, 0, 0, time.UTC)),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccKeyPairResource_preferences(t *testing.T) {
+	for _, profile := range []string{"gnupg", "rfc9580"} {
+		t.Run(profile, func(t *testing.T) {
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					// Unknown algorithms and AEAD ciphersuites without SEIPDv2 are rejected
+					{
+						Config:      testAccKeyPairResourcePreferencesConfig(profile, `ciphers = ["aes256", "rot13"]`),
+						ExpectError: regexp.MustCompile(`unknown cipher "rot13"`),
+					},
+					{
+						Config:      testAccKeyPairResourcePreferencesConfig(profile, `aead_ciphersuites = ["aes256/ocb"]`+"\n"+`features = ["mdc"]`),
+						ExpectError: regexp.MustCompile(`aead_ciphersuites require the feature "seipdv2"`),
+					},
+					// Create and Read testing
+					{
+						Config: testAccKeyPairResourcePreferencesConfig(profile, `
+    ciphers           = ["aes256", "aes128"]
+    hashes            = ["sha512", "sha256"]
+    compression       = ["none"]
+    aead_ciphersuites = ["aes256/ocb", "aes128/gcm"]
+    features          = ["seipdv2"]
+`),
+						Check: resource.ComposeAggregateTestCheckFunc(
+							resource.TestCheckResourceAttrSet("gpg_key_pair.test", "fingerprint"),
+							testAccCheckGpgKeyPairPreferences("gpg_key_pair.test", func(sig *packet.Signature) error {
+								if string(sig.PreferredSymmetric) != "\x09\x07" || string(sig.PreferredHash) != "\x0a\x08" || string(sig.PreferredCompression) != "\x00" {
+									return fmt.Errorf("unexpected preferences %v %v %v", sig.PreferredSymmetric, sig.PreferredHash, sig.PreferredCompression)
+								}
+								if fmt.Sprint(sig.PreferredCipherSuites) != "[[9 2] [7 3]]" {
+									return fmt.Errorf("unexpected AEAD ciphersuites %v", sig.PreferredCipherSuites)
+								}
+								if sig.SEIPDv1 || !sig.SEIPDv2 {
+									return fmt.Errorf("unexpected features SEIPDv1=%t SEIPDv2=%t", sig.SEIPDv1, sig.SEIPDv2)
+								}
+								return nil
+							}),
+						),
+					},
+					// Changing the preferences forces a new key
+					{
+						Config: testAccKeyPairResourcePreferencesConfig(profile, `hashes = ["sha256"]`),
+						ConfigPlanChecks: resource.ConfigPlanChecks{
+							PreApply: []plancheck.PlanCheck{
+								plancheck.ExpectResourceAction("gpg_key_pair.test", plancheck.ResourceActionReplace),
+							},
+						},
+						Check: testAccCheckGpgKeyPairPreferences("gpg_key_pair.test", func(sig *packet.Signature) error {
+							if string(sig.PreferredHash) != "\x08" || len(sig.PreferredSymmetric) == 0 {
+								return fmt.Errorf("unexpected preferences %v %v", sig.PreferredSymmetric, sig.PreferredHash)
+							}
+							return nil
+						}),
+					},
+					// Delete testing automatically occurs in TestCase
+				},
+			})
+		})
+	}
+}
+
+func TestAccKeyPairResource_escrow(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccKeyPairResourceEscrowConfig("[gpg_key_pair.escrow[0].public_key]"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgKeyPairEscrow("gpg_key_pair.test", "gpg_key_pair.escrow.0"),
+				),
+			},
+			// Update testing, changing the recipients only encrypts the private key again
+			{
+				Config: testAccKeyPairResourceEscrowConfig("gpg_key_pair.escrow[*].public_key"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_key_pair.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgKeyPairEscrow("gpg_key_pair.test", "gpg_key_pair.escrow.0"),
+					testAccCheckGpgKeyPairEscrow("gpg_key_pair.test", "gpg_key_pair.escrow.1"),
+				),
+			},
+			{
+				Config: testAccKeyPairResourceEscrowConfig("null"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_key_pair.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("gpg_key_pair.test", "escrowed_private_key"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+// testAccCheckGpgKeyPairPreferences checks the preferences of all self-signatures carrying preferences, which must
+// still be valid.
+func testAccCheckGpgKeyPairPreferences(name string, check func(sig *packet.Signature) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+
+		key, err := crypto.NewKeyFromArmored(rs.Primary.Attributes["public_key"])
+		if err != nil {
+			return err
+		}
+
+		entity := key.GetEntity()
+		var sigs []*packet.Signature
+		for _, direct := range entity.DirectSignatures {
+			if err := entity.PrimaryKey.VerifyDirectKeySignature(direct.Packet); err != nil {
+				return fmt.Errorf("invalid direct key signature: %w", err)
+			}
+			sigs = append(sigs, direct.Packet)
+		}
+		for _, identity := range entity.Identities {
+			for _, sig := range identity.SelfCertifications {
+				if err := entity.PrimaryKey.VerifyUserIdSignature(identity.Name, entity.PrimaryKey, sig.Packet); err != nil {
+					return fmt.Errorf("invalid self-signature of %s: %w", identity.Name, err)
+				}
+				sigs = append(sigs, sig.Packet)
+			}
+		}
+
+		checked := 0
+		for _, sig := range sigs {
+			if sig.PreferredSymmetric == nil {
+				continue
+			}
+			if err := check(sig); err != nil {
+				return err
+			}
+			checked++
+		}
+		if checked == 0 {
+			return fmt.Errorf("no self-signature with preferences found")
+		}
+		return nil
+	}
+}
+
+// testAccCheckGpgKeyPairCreationTime checks the creation time of all key packets and self-signatures.
+func testAccCheckGpgKeyPairCreationTime(name string, creationTime time.Time) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+
+		key, err := crypto.NewKeyFromArmored(rs.Primary.Attributes["public_key"])
+		if err != nil {
+			return err
+		}
+
+		entity := key.GetEntity()
+		times := map[string]time.Time{"primary key": entity.PrimaryKey.CreationTime}
+		for _, identity := range entity.Identities {
+			for _, sig := range identity.SelfCertifications {
+				times["self-signature of "+identity.Name] = sig.Packet.CreationTime
+			}
+		}
+		for i, subkey := range entity.Subkeys {
+			times[fmt.Sprintf("subkey %d", i)] = subkey.PublicKey.CreationTime
+			for _, binding := range subkey.Bindings {
+				times[fmt.Sprintf("binding of subkey %d", i)] = binding.Packet.CreationTime
+			}
+		}
+		for what, actual := range times {
+			if !actual.Equal(creationTime) {
+				return fmt.Errorf("unexpected creation time of %s: %s", what, actual)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccCheckGpgKeyPairSSHPrivateKey checks that the SSH private key belongs to the SSH public key.
+func testAccCheckGpgKeyPairSSHPrivateKey(name string, encrypted bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+
+		privateKey := []byte(rs.Primary.Attributes["ssh_private_key"])
+		signer, err := ssh.ParsePrivateKey(privateKey)
+		if encrypted {
+			if _, ok := err.(*ssh.PassphraseMissingError); !ok {
+				return fmt.Errorf("expected an encrypted SSH private key, got error %v", err)
+			}
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(privateKey, []byte(rs.Primary.Attributes["passphrase"]))
+		}
+		if err != nil {
+			return err
+		}
+
+		publicKey := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(signer.PublicKey())), "\n")
+		if !strings.HasPrefix(rs.Primary.Attributes["ssh_public_key"], publicKey+" ") {
+			return fmt.Errorf("SSH private key does not match SSH public key %q", rs.Primary.Attributes["ssh_public_key"])
+		}
+		return nil
+	}
+}
+
+// testAccCheckGpgKeyPairBase64 checks that the keys in base64 format are the keys in hex format.
+func testAccCheckGpgKeyPairBase64(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+
+		for _, key := range []string{"public_key", "private_key"} {
+			decoded, err := base64.StdEncoding.DecodeString(rs.Primary.Attributes[key+"_base64"])
+			if err != nil {
+				return err
+			}
+			if hex.EncodeToString(decoded) != rs.Primary.Attributes[key+"_hex"] {
+				return fmt.Errorf("expected %s_base64 to be %s_hex in base64 format", key, key)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccCheckOutputEqualsAttr checks that the output equals the attribute of the resource.
+func testAccCheckOutputEqualsAttr(output string, name string, key string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+		return resource.TestCheckOutput(output, rs.Primary.Attributes[key])(s)
+	}
+}
+
+// testAccCheckGpgKeyPairEscrow checks that the escrow key decrypts the escrowed private key to the locked private key
+// of the key pair.
+func testAccCheckGpgKeyPairEscrow(name string, escrowName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+		escrow, ok := s.RootModule().Resources[escrowName]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", escrowName)
+		}
+
+		escrowKey, err := crypto.NewKeyFromArmored(escrow.Primary.Attributes["private_key"])
+		if err != nil {
+			return err
+		}
+		escrowKey, err = escrowKey.Unlock([]byte(escrow.Primary.Attributes["passphrase"]))
+		if err != nil {
+			return err
+		}
+		handle, err := crypto.PGP().Decryption().DecryptionKey(escrowKey).New()
+		if err != nil {
+			return err
+		}
+		result, err := handle.Decrypt([]byte(rs.Primary.Attributes["escrowed_private_key"]), crypto.Armor)
+		if err != nil {
+			return err
+		}
+
+		privateKey, err := crypto.NewKeyFromArmored(result.String())
+		if err != nil {
+			return err
+		}
+		if privateKey.GetFingerprint() != rs.Primary.Attributes["fingerprint"] {
+			return fmt.Errorf("escrowed private key %s does not match %s", privateKey.GetFingerprint(), rs.Primary.Attributes["fingerprint"])
+		}
+		if locked, err := privateKey.IsLocked(); err != nil || !locked {
+			return fmt.Errorf("expected the escrowed private key to be locked")
+		}
+		if _, err := privateKey.Unlock([]byte(rs.Primary.Attributes["passphrase"])); err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
+func testAccCheckGpgKeyPairVersion(name string, version int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+
+		privateKey, err := crypto.NewKeyFromArmored(rs.Primary.Attributes["private_key"])
+		if err != nil {
+			return err
+		}
+
+		privateKey, err = privateKey.Unlock([]byte(rs.Primary.Attributes["passphrase"]))
+		if err != nil {
+			return err
+		}
+
+		if privateKey.GetVersion() != version {
+			return fmt.Errorf("unexpected key version %d", privateKey.GetVersion())
+		}
+		return nil
+	}
+}
+
 func testAccCheckGpgKeyPair(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+		return testAccCheckGpgKeyPairWithPassphrase(name, rs.Primary.Attributes["passphrase"])(s)
+	}
+}
+
+// testAccCheckGpgKeyPairWithPassphrase checks that the private key of the key pair is locked with the passphrase.
+func testAccCheckGpgKeyPairWithPassphrase(name string, passphrase string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
 		if !ok {
@@ -59,8 +717,7 @@ func testAccCheckGpgKeyPair(name string) resource.TestCheckFunc {
 			return fmt.Errorf("expected key to be locked")
 		}
 
-		passphrase := rs.Primary.Attributes["passphrase"]
-		privateKey, err = privateKey.Unlock(unsafe.Slice(unsafe.StringData(passphrase), len(passphrase)))
+		privateKey, err = privateKey.Unlock([]byte(passphrase))
 		if err != nil {
 			return err
 		}
@@ -96,3 +753,163 @@ resource "gpg_key_pair" "test" {
 }
 `, name, email, passphrase)
 }
+
+func testAccKeyPairResourceProviderDefaultsConfig(attributes string) string {
+	return fmt.Sprintf(`
+provider "gpg" {
+  default_profile = "rfc9580"
+  default_expiry  = "17520h"
+  default_s2k = {
+	mode          = "argon2"
+	argon2_passes = 1
+	argon2_memory = 1024
+  }
+  default_comment = "managed by terraform"
+  default_wkd_base = "https://openpgpkey.example.com/.well-known/openpgpkey/example.com"
+}
+
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+  %[1]s
+}
+`, attributes)
+}
+
+func testAccKeyPairResourceAuthenticationSubkeyConfig(profile string, attributes string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase            = "top secret"
+  profile               = %[1]q
+  authentication_subkey = true
+
+  %[2]s
+}
+
+output "ssh_public_key" {
+  value = provider::gpg::to_ssh_public_key(gpg_key_pair.test.public_key)
+}
+`, profile, attributes)
+}
+
+func testAccKeyPairResourceWriteOnlyConfig(passphrase string, version int) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase_wo      = %[1]q
+  passphrase_version = %[2]d
+}
+`, passphrase, version)
+}
+
+func testAccKeyPairResourceDeterministicConfig(profile string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase            = "top secret"
+  profile               = %[1]q
+  deterministic_seed    = "fixture"
+  authentication_subkey = true
+}
+
+resource "gpg_key_pair" "same" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase            = "top secret"
+  profile               = %[1]q
+  deterministic_seed    = "fixture"
+  authentication_subkey = true
+}
+
+resource "gpg_key_pair" "other" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase         = "top secret"
+  profile            = %[1]q
+  deterministic_seed = "other fixture"
+}
+`, profile)
+}
+
+func testAccKeyPairResourceCreationTimeConfig(creationTime string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase            = "top secret"
+  creation_time         = %[1]q
+  authentication_subkey = true
+}
+`, creationTime)
+}
+
+func testAccKeyPairResourceWKDConfig(attributes string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+  %[1]s
+}
+`, attributes)
+}
+
+func testAccKeyPairResourcePreferencesConfig(profile string, preferences string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+  profile    = %[1]q
+  preferences = {
+    %[2]s
+  }
+}
+`, profile, preferences)
+}
+
+func testAccKeyPairResourceEscrowConfig(escrowRecipients string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "escrow" {
+  count = 2
+
+  identities = [{
+	name  = "Escrow Agent"
+	email = "escrow${count.index}@example.com"
+  }]
+  passphrase = "escrow secret"
+}
+
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase        = "top secret"
+  escrow_recipients = %[1]s
+}
+`, escrowRecipients)
+}
@@ -13,7 +13,6 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"unsafe"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
@@ -114,7 +113,7 @@ func keySchema() *schema.Schema {
 }
 
 func (g KeyResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
-	var data keyPairModelV1
+	var data keyModelV1
 
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 
@@ -133,7 +132,7 @@ func (g KeyResource) ValidateConfig(ctx context.Context, req resource.ValidateCo
 }
 
 func (g KeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var data keyPairModelV1
+	var data keyModelV1
 
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -157,7 +156,7 @@ func (g KeyResource) Create(ctx context.Context, req resource.CreateRequest, res
 	}
 	defer key.ClearPrivateParams()
 
-	key, err = pgp.LockKey(key, unsafe.Slice(unsafe.StringData(data.Passphrase.ValueString()), len(data.Passphrase.ValueString())))
+	key, err = pgp.LockKey(key, []byte(data.Passphrase.ValueString()))
 	if err != nil {
 		resp.Diagnostics.AddError("GPG key generation failed", fmt.Sprintf("LockKey failed with error: %s", err))
 		return
@@ -204,7 +203,7 @@ func (g KeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *r
 
 // Update ensures the plan value is copied to the state to complete the update.
 func (g KeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var model keyPairModelV1
+	var model keyModelV1
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &model)...)
 
@@ -218,3 +217,14 @@ func (g KeyResource) Update(ctx context.Context, req resource.UpdateRequest, res
 func (g KeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	// Nothing to do here.
 }
+
+type keyModelV1 struct {
+	Id            types.String      `tfsdk:"id"`
+	Identities    []identityModelV1 `tfsdk:"identities"`
+	Passphrase    types.String      `tfsdk:"passphrase"`
+	Fingerprint   types.String      `tfsdk:"fingerprint"`
+	PrivateKey    types.String      `tfsdk:"private_key"`
+	PrivateKeyHex types.String      `tfsdk:"private_key_hex"`
+	PublicKey     types.String      `tfsdk:"public_key"`
+	PublicKeyHex  types.String      `tfsdk:"public_key_hex"`
+}
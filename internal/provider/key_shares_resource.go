@@ -0,0 +1,291 @@
+package provider
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &KeySharesResource{}
+var _ resource.ResourceWithConfigure = &KeySharesResource{}
+var _ resource.ResourceWithValidateConfig = &KeySharesResource{}
+
+func NewKeySharesResource() resource.Resource {
+	return &KeySharesResource{}
+}
+
+type KeySharesResource struct {
+	// defaults holds the provider configuration, it is nil if the provider has not been configured.
+	defaults *GpgProviderModel
+}
+
+func (g *KeySharesResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	defaults, ok := req.ProviderData.(*GpgProviderModel)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *GpgProviderModel, got %T.", req.ProviderData))
+		return
+	}
+	g.defaults = defaults
+}
+
+func (g KeySharesResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_key_shares"
+}
+
+func (g KeySharesResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A resource for splitting a private key into shares with Shamir's secret sharing scheme",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprint of the split key.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"private_key": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private key to split in armored format. Exactly one of `private_key` and `private_key_hex` must be set.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"private_key_hex": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private key to split in hex format. Exactly one of `private_key` and `private_key_hex` must be set.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"share_count": schema.Int64Attribute{
+				Required:            true,
+				MarkdownDescription: "Number of shares to create, at most 255.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"threshold": schema.Int64Attribute{
+				Required:            true,
+				MarkdownDescription: "Number of shares needed to recover the key, at least 2 and at most `share_count`.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"custodian_public_keys": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Public keys of the custodians in armored format, one per share. If set, every share is encrypted to the public key at the same index.",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+			"fingerprint": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprint of the split key.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"shares": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Shares of the private key, encrypted messages in armored format if `custodian_public_keys` is set. The shares can be combined with the `combine_shares` function.",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (g KeySharesResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data keySharesModelV1
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.PrivateKey.IsNull() == data.PrivateKeyHex.IsNull() {
+		resp.Diagnostics.AddError("Invalid private key", "Exactly one of private_key and private_key_hex must be set.")
+	}
+
+	if data.ShareCount.IsUnknown() || data.Threshold.IsUnknown() {
+		return
+	}
+	if data.ShareCount.ValueInt64() < 2 || data.ShareCount.ValueInt64() > 255 {
+		resp.Diagnostics.AddAttributeError(path.Root("share_count"), "Invalid share count", "share_count must be between 2 and 255.")
+	}
+	if data.Threshold.ValueInt64() < 2 || data.Threshold.ValueInt64() > data.ShareCount.ValueInt64() {
+		resp.Diagnostics.AddAttributeError(path.Root("threshold"), "Invalid threshold", "threshold must be between 2 and share_count.")
+	}
+	if !data.CustodianPublicKeys.IsNull() && !data.CustodianPublicKeys.IsUnknown() && int64(len(data.CustodianPublicKeys.Elements())) != data.ShareCount.ValueInt64() {
+		resp.Diagnostics.AddAttributeError(path.Root("custodian_public_keys"), "Invalid custodian public keys", "custodian_public_keys must contain exactly share_count keys.")
+	}
+}
+
+func (g KeySharesResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data keySharesModelV1
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var key *gpgcrypto.Key
+	var err error
+	if !data.PrivateKey.IsNull() {
+		key, err = gpgcrypto.NewKeyFromArmored(data.PrivateKey.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("private_key"), "GPG key splitting failed", fmt.Sprintf("NewKeyFromArmored failed with error: %s", err))
+			return
+		}
+	} else {
+		privateKeyHex, err := hex.DecodeString(data.PrivateKeyHex.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("private_key_hex"), "GPG key splitting failed", fmt.Sprintf("DecodeString failed with error: %s", err))
+			return
+		}
+		key, err = gpgcrypto.NewKey(privateKeyHex)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("private_key_hex"), "GPG key splitting failed", fmt.Sprintf("NewKey failed with error: %s", err))
+			return
+		}
+	}
+	if !key.IsPrivate() {
+		resp.Diagnostics.AddError("GPG key splitting failed", "The key is not a private key.")
+		return
+	}
+
+	secret, err := key.Serialize()
+	if err != nil {
+		resp.Diagnostics.AddError("GPG key splitting failed", fmt.Sprintf("Serialize failed with error: %s", err))
+		return
+	}
+	defer clear(secret)
+
+	keyShares, err := splitSecret(secret, key.GetFingerprint(), int(data.ShareCount.ValueInt64()), int(data.Threshold.ValueInt64()))
+	if err != nil {
+		resp.Diagnostics.AddError("GPG key splitting failed", fmt.Sprintf("splitSecret failed with error: %s", err))
+		return
+	}
+
+	var custodians []string
+	if !data.CustodianPublicKeys.IsNull() {
+		resp.Diagnostics.Append(data.CustodianPublicKeys.ElementsAs(ctx, &custodians, false)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	var defaults GpgProviderModel
+	if g.defaults != nil {
+		defaults = *g.defaults
+	}
+
+	keyProfile, err := profileByName(resolveString(types.StringNull(), defaults.DefaultProfile, defaultProfileName).ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("GPG key splitting failed", err.Error())
+		return
+	}
+	var pgp = gpgcrypto.PGPWithProfile(keyProfile)
+
+	shares := make([]string, len(keyShares))
+	for i, share := range keyShares {
+		shares[i] = share.String()
+		if custodians == nil {
+			continue
+		}
+
+		custodian, err := gpgcrypto.NewKeyFromArmored(custodians[i])
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("custodian_public_keys").AtListIndex(i), "GPG key splitting failed", fmt.Sprintf("NewKeyFromArmored failed with error: %s", err))
+			return
+		}
+
+		handle, err := pgp.Encryption().Recipient(custodian).New()
+		if err != nil {
+			resp.Diagnostics.AddError("GPG key splitting failed", fmt.Sprintf("New failed with error: %s", err))
+			return
+		}
+
+		message, err := handle.Encrypt([]byte(shares[i]))
+		if err != nil {
+			resp.Diagnostics.AddError("GPG key splitting failed", fmt.Sprintf("Encrypt failed with error: %s", err))
+			return
+		}
+
+		shares[i], err = message.ArmorWithCustomHeaders(resolveString(types.StringNull(), defaults.DefaultComment, "").ValueString(), "")
+		if err != nil {
+			resp.Diagnostics.AddError("GPG key splitting failed", fmt.Sprintf("Armor failed with error: %s", err))
+			return
+		}
+	}
+
+	data.Id = types.StringValue(key.GetFingerprint())
+	data.Fingerprint = types.StringValue(key.GetFingerprint())
+
+	sharesValue, diags := types.ListValueFrom(ctx, types.StringType, shares)
+	resp.Diagnostics.Append(diags...)
+	data.Shares = sharesValue
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (g KeySharesResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Nothing to do here.
+}
+
+// Update ensures the plan value is copied to the state to complete the update.
+func (g KeySharesResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var model keySharesModelV1
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &model)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
+
+func (g KeySharesResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here.
+}
+
+type keySharesModelV1 struct {
+	Id                  types.String `tfsdk:"id"`
+	PrivateKey          types.String `tfsdk:"private_key"`
+	PrivateKeyHex       types.String `tfsdk:"private_key_hex"`
+	ShareCount          types.Int64  `tfsdk:"share_count"`
+	Threshold           types.Int64  `tfsdk:"threshold"`
+	CustodianPublicKeys types.List   `tfsdk:"custodian_public_keys"`
+	Fingerprint         types.String `tfsdk:"fingerprint"`
+	Shares              types.List   `tfsdk:"shares"`
+}
@@ -0,0 +1,165 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccKeySharesResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccKeySharesResourceConfig("[gpg_key_shares.test.shares[0], gpg_key_shares.test.shares[2]]"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("gpg_key_shares.test", "fingerprint", "gpg_key_pair.test", "fingerprint"),
+					resource.TestCheckResourceAttr("gpg_key_shares.test", "shares.#", "3"),
+					testAccCheckGpgPrivateKeyOutput("combined", "gpg_key_pair.test"),
+				),
+			},
+			// Combining fewer shares than the threshold fails
+			{
+				Config:      testAccKeySharesResourceConfig("[gpg_key_shares.test.shares[1]]"),
+				ExpectError: regexp.MustCompile(`1 shares given, but 2 are needed`),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccKeySharesResource_custodians(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccKeySharesResourceCustodiansConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_key_shares.test", "shares.#", "2"),
+					testAccCheckGpgKeyShares("gpg_key_shares.test", "gpg_key_pair.test", "gpg_key_pair.custodian"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+// testAccCheckGpgKeyShares decrypts the shares with the private keys of the custodians and checks that they combine to
+// the private key of the key pair.
+func testAccCheckGpgKeyShares(name string, keyName string, custodianPrefix string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+		keyRs, ok := s.RootModule().Resources[keyName]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", keyName)
+		}
+
+		count, err := strconv.Atoi(rs.Primary.Attributes["shares.#"])
+		if err != nil {
+			return err
+		}
+		keyShares := make([]keyShare, count)
+		for i := range keyShares {
+			custodianRs, ok := s.RootModule().Resources[fmt.Sprintf("%s.%d", custodianPrefix, i)]
+			if !ok {
+				return fmt.Errorf("could not find resource at path %s.%d", custodianPrefix, i)
+			}
+			custodian, err := crypto.NewPrivateKeyFromArmored(custodianRs.Primary.Attributes["private_key"], []byte(custodianRs.Primary.Attributes["passphrase"]))
+			if err != nil {
+				return err
+			}
+			decryption, err := crypto.PGP().Decryption().DecryptionKey(custodian).New()
+			if err != nil {
+				return err
+			}
+			result, err := decryption.Decrypt([]byte(rs.Primary.Attributes[fmt.Sprintf("shares.%d", i)]), crypto.Armor)
+			if err != nil {
+				return err
+			}
+			keyShares[i], err = parseKeyShare(string(result.Bytes()))
+			if err != nil {
+				return err
+			}
+		}
+
+		secret, err := combineShares(keyShares)
+		if err != nil {
+			return err
+		}
+		key, err := crypto.NewKey(secret)
+		if err != nil {
+			return err
+		}
+		if key.GetFingerprint() != keyRs.Primary.Attributes["fingerprint"] {
+			return fmt.Errorf("combined key has fingerprint %s, expected %s", key.GetFingerprint(), keyRs.Primary.Attributes["fingerprint"])
+		}
+		return nil
+	}
+}
+
+func testAccKeySharesResourceConfig(shares string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+}
+
+resource "gpg_key_shares" "test" {
+  private_key = gpg_key_pair.test.private_key
+  share_count = 3
+  threshold   = 2
+}
+
+output "combined" {
+  value     = provider::gpg::combine_shares(%[1]s, gpg_key_pair.test.fingerprint)
+  sensitive = true
+}
+`, shares)
+}
+
+func testAccKeySharesResourceCustodiansConfig() string {
+	return `
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+}
+
+resource "gpg_key_pair" "custodian" {
+  count = 2
+  identities = [{
+	name  = "Custodian ${count.index}"
+	email = "custodian${count.index}@example.com"
+  }]
+  passphrase = "custodian secret"
+}
+
+resource "gpg_key_shares" "test" {
+  private_key_hex       = gpg_key_pair.test.private_key_hex
+  share_count           = 2
+  threshold             = 2
+  custodian_public_keys = gpg_key_pair.custodian[*].public_key
+}
+`
+}
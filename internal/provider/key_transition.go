@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"bytes"
+	"crypto"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v3/armor"
+	"github.com/ProtonMail/gopenpgp/v3/constants"
+	"io"
+	"strings"
+	"time"
+)
+
+// keyTransitionStatement returns the text of a statement that the key with the old fingerprint is replaced by the key
+// with the new fingerprint.
+func keyTransitionStatement(oldFingerprint string, newFingerprint string, date time.Time, reason string) string {
+	return fmt.Sprintf("OpenPGP key transition statement\n\nOld key: %s\nNew key: %s\nDate: %s\nReason: %s\n",
+		strings.ToUpper(oldFingerprint), strings.ToUpper(newFingerprint), date.UTC().Format(time.RFC3339), reason)
+}
+
+// clearsignWithPrimaryKeys clearsigns the text with all unlocked primary keys, so that the signature can be verified
+// with each of the keys.
+func clearsignWithPrimaryKeys(text string, privateKeys ...*packet.PrivateKey) (string, error) {
+	checksum := true
+	for _, privateKey := range privateKeys {
+		if privateKey.Dummy() {
+			return "", fmt.Errorf("key %X has no secret primary key", privateKey.Fingerprint)
+		}
+		checksum = checksum && privateKey.Version != 6
+	}
+
+	var buf bytes.Buffer
+	plaintext, err := clearsign.EncodeMulti(&buf, privateKeys, &packet.Config{DefaultHash: crypto.SHA512})
+	if err != nil {
+		return "", err
+	}
+	if _, err := plaintext.Write([]byte(text)); err != nil {
+		return "", err
+	}
+	if err := plaintext.Close(); err != nil {
+		return "", err
+	}
+
+	// The encoder armors the signatures without checksum, which gpg 2.2 rejects, so they are armored again with
+	// checksum unless one of the keys is version 6.
+	block, _ := clearsign.Decode(buf.Bytes())
+	if block == nil {
+		return "", fmt.Errorf("clearsigned message could not be decoded")
+	}
+	signatures, err := io.ReadAll(block.ArmoredSignature.Body)
+	if err != nil {
+		return "", err
+	}
+	armoredSignatures, err := armor.ArmorWithTypeAndCustomHeadersChecksum(signatures, constants.PGPSignatureHeader, "", "", checksum)
+	if err != nil {
+		return "", err
+	}
+	signed, _, _ := bytes.Cut(buf.Bytes(), []byte("-----BEGIN "+constants.PGPSignatureHeader))
+	return string(signed) + armoredSignatures + "\n", nil
+}
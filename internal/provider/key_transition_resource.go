@@ -0,0 +1,284 @@
+package provider
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &KeyTransitionResource{}
+var _ resource.ResourceWithConfigure = &KeyTransitionResource{}
+
+func NewKeyTransitionResource() resource.Resource {
+	return &KeyTransitionResource{}
+}
+
+type KeyTransitionResource struct {
+	// defaults holds the provider configuration, it is nil if the provider has not been configured.
+	defaults *GpgProviderModel
+}
+
+func (g *KeyTransitionResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	defaults, ok := req.ProviderData.(*GpgProviderModel)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *GpgProviderModel, got %T.", req.ProviderData))
+		return
+	}
+	g.defaults = defaults
+}
+
+func (g KeyTransitionResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_key_transition"
+}
+
+func (g KeyTransitionResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A resource for a key transition statement, which proves to partners that a new key replaces an old key. " +
+			"The statement names the fingerprints of both keys, the date and the reason, and is clearsigned by both keys.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprints of the old and the new key separated by a colon.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"old_private_key": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private key in armored format of the key that is replaced.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"old_passphrase": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Passphrase for unlocking the old private key.",
+			},
+			"new_private_key": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private key in armored format of the key that replaces the old key.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"new_passphrase": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Passphrase for unlocking the new private key.",
+			},
+			"reason": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Reason for the transition, e.g. `Migration to a stronger key.`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"certify_new_key": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether to also certify the user IDs of the new key with the old key, so that partners who trust the old key can validate the new key. Defaults to `false`.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"old_fingerprint": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprint of the old key.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"new_fingerprint": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprint of the new key.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"date": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Date of the transition statement in RFC 3339 format.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"statement": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Transition statement clearsigned by the old and the new key.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"certified_public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the new key in armored format with the certifications of the old key, if `certify_new_key` is set.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (g KeyTransitionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data keyTransitionModelV1
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var defaults GpgProviderModel
+	if g.defaults != nil {
+		defaults = *g.defaults
+	}
+
+	keyProfile, err := profileByName(resolveString(types.StringNull(), defaults.DefaultProfile, defaultProfileName).ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("GPG key transition failed", err.Error())
+		return
+	}
+	config := keyProfile.SignConfig()
+
+	oldKey, diags := readUnlockedPrivateKey(path.Root("old_private_key"), data.OldPrivateKey.ValueString(), path.Root("old_passphrase"), data.OldPassphrase.ValueString())
+	resp.Diagnostics.Append(diags...)
+	newKey, diags := readUnlockedPrivateKey(path.Root("new_private_key"), data.NewPrivateKey.ValueString(), path.Root("new_passphrase"), data.NewPassphrase.ValueString())
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	oldFingerprint := hex.EncodeToString(oldKey.PrimaryKey.Fingerprint)
+	newFingerprint := hex.EncodeToString(newKey.PrimaryKey.Fingerprint)
+	if oldFingerprint == newFingerprint {
+		resp.Diagnostics.AddAttributeError(path.Root("new_private_key"), "GPG key transition failed", "The new key must differ from the old key.")
+		return
+	}
+
+	now := time.Now()
+	statement, err := clearsignWithPrimaryKeys(keyTransitionStatement(oldFingerprint, newFingerprint, now, data.Reason.ValueString()), oldKey.PrivateKey, newKey.PrivateKey)
+	if err != nil {
+		resp.Diagnostics.AddError("GPG key transition failed", fmt.Sprintf("Clearsign failed with error: %s", err))
+		return
+	}
+
+	data.CertifiedPublicKey = types.StringNull()
+	if data.CertifyNewKey.ValueBool() {
+		certificationKey, ok := oldKey.CertificationKey(config.Now())
+		if !ok {
+			resp.Diagnostics.AddAttributeError(path.Root("old_private_key"), "GPG key transition failed", "The old key has no valid certification key.")
+			return
+		}
+
+		uids := make([]string, 0, len(newKey.Identities))
+		for uid := range newKey.Identities {
+			uids = append(uids, uid)
+		}
+		sort.Strings(uids)
+
+		if err := certifyUserIds(newKey, uids, certificationKey, packet.SigTypeGenericCert, now, config, nil); err != nil {
+			resp.Diagnostics.AddError("GPG key transition failed", fmt.Sprintf("SignUserId failed with error: %s", err))
+			return
+		}
+
+		certifiedPublicKey, err := armorPublicKey(newKey, newKey.PrimaryKey.Version, resolveString(types.StringNull(), defaults.DefaultComment, "").ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("GPG key transition failed", fmt.Sprintf("Armor failed with error: %s", err))
+			return
+		}
+		data.CertifiedPublicKey = types.StringValue(certifiedPublicKey)
+	}
+
+	data.Id = types.StringValue(oldFingerprint + ":" + newFingerprint)
+	data.OldFingerprint = types.StringValue(oldFingerprint)
+	data.NewFingerprint = types.StringValue(newFingerprint)
+	data.Date = types.StringValue(now.UTC().Format(time.RFC3339))
+	data.Statement = types.StringValue(statement)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (g KeyTransitionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Nothing to do here.
+}
+
+// Update ensures the plan value is copied to the state to complete the update.
+func (g KeyTransitionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var model keyTransitionModelV1
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &model)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
+
+func (g KeyTransitionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here.
+}
+
+type keyTransitionModelV1 struct {
+	Id                 types.String `tfsdk:"id"`
+	OldPrivateKey      types.String `tfsdk:"old_private_key"`
+	OldPassphrase      types.String `tfsdk:"old_passphrase"`
+	NewPrivateKey      types.String `tfsdk:"new_private_key"`
+	NewPassphrase      types.String `tfsdk:"new_passphrase"`
+	Reason             types.String `tfsdk:"reason"`
+	CertifyNewKey      types.Bool   `tfsdk:"certify_new_key"`
+	OldFingerprint     types.String `tfsdk:"old_fingerprint"`
+	NewFingerprint     types.String `tfsdk:"new_fingerprint"`
+	Date               types.String `tfsdk:"date"`
+	Statement          types.String `tfsdk:"statement"`
+	CertifiedPublicKey types.String `tfsdk:"certified_public_key"`
+}
+
+// readUnlockedPrivateKey reads exactly one armored private key and unlocks it with the passphrase. Errors are reported
+// for the attributes of the key and the passphrase.
+func readUnlockedPrivateKey(keyPath path.Path, armoredKey string, passphrasePath path.Path, passphrase string) (*openpgp.Entity, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredKey))
+	if err != nil {
+		diags.AddAttributeError(keyPath, "GPG key transition failed", fmt.Sprintf("ReadArmoredKeyRing failed with error: %s", err))
+		return nil, diags
+	}
+	if len(entities) != 1 || entities[0].PrivateKey == nil {
+		diags.AddAttributeError(keyPath, "GPG key transition failed", "Expected exactly one private key.")
+		return nil, diags
+	}
+	if entities[0].PrivateKey.Dummy() {
+		diags.AddAttributeError(keyPath, "GPG key transition failed", "The secret key material of the primary key is required to sign the statement.")
+		return nil, diags
+	}
+	if err := entities[0].DecryptPrivateKeys([]byte(passphrase)); err != nil {
+		diags.AddAttributeError(passphrasePath, "GPG key transition failed", fmt.Sprintf("DecryptPrivateKeys failed with error: %s", err))
+		return nil, diags
+	}
+	return entities[0], diags
+}
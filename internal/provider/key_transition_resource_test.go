@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccKeyTransitionResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccKeyTransitionResourceConfig("new", false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("gpg_key_transition.test", "old_fingerprint", "gpg_key_pair.old", "fingerprint"),
+					resource.TestCheckResourceAttrPair("gpg_key_transition.test", "new_fingerprint", "gpg_key_pair.new", "fingerprint"),
+					resource.TestCheckResourceAttrWith("gpg_key_transition.test", "statement", func(value string) error {
+						if !strings.Contains(value, "\nReason: Migration to a new key.\n") {
+							return fmt.Errorf("expected the reason in the statement:\n%s", value)
+						}
+						return nil
+					}),
+					resource.TestCheckNoResourceAttr("gpg_key_transition.test", "certified_public_key"),
+					testAccCheckGpgKeyTransition("gpg_key_transition.test", filepath.Join(t.TempDir(), "gnupg")),
+				),
+			},
+			// Replace testing
+			{
+				Config: testAccKeyTransitionResourceConfig("new", true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgKeyTransition("gpg_key_transition.test", filepath.Join(t.TempDir(), "gnupg")),
+					testAccCheckGpgKeyTransitionCertification("gpg_key_transition.test"),
+				),
+			},
+			{
+				Config:      testAccKeyTransitionResourceConfig("old", true),
+				ExpectError: regexp.MustCompile(`The new key must differ from the old key`),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+// testAccCheckGpgKeyTransition checks the statement of the key transition against the public keys of the key pairs.
+func testAccCheckGpgKeyTransition(name string, home string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+		return testAccVerifyKeyTransitionStatement(
+			rs.Primary.Attributes["statement"],
+			s.RootModule().Resources["gpg_key_pair.old"].Primary.Attributes["public_key"],
+			s.RootModule().Resources["gpg_key_pair.new"].Primary.Attributes["public_key"],
+			home,
+		)
+	}
+}
+
+// testAccCheckGpgKeyTransitionCertification checks that all user IDs of the certified public key are certified by the
+// old key and that version 4 keys are armored with a checksum.
+func testAccCheckGpgKeyTransitionCertification(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+
+		signers, err := openpgp.ReadArmoredKeyRing(strings.NewReader(s.RootModule().Resources["gpg_key_pair.old"].Primary.Attributes["public_key"]))
+		if err != nil {
+			return err
+		}
+		keys, err := openpgp.ReadArmoredKeyRing(strings.NewReader(rs.Primary.Attributes["certified_public_key"]))
+		if err != nil {
+			return err
+		}
+		if len(keys) != 1 || keys[0].PrivateKey != nil || len(keys[0].Identities) == 0 {
+			return fmt.Errorf("expected exactly one public key with user IDs")
+		}
+		// Version 4 keys are armored with a checksum like the keys of the key pair resource.
+		if keys[0].PrimaryKey.Version == 4 && !regexp.MustCompile(`\n=[A-Za-z0-9+/]{4}\n-----END PGP PUBLIC KEY BLOCK-----`).MatchString(rs.Primary.Attributes["certified_public_key"]) {
+			return fmt.Errorf("expected an armor checksum")
+		}
+
+	identities:
+		for _, identity := range keys[0].Identities {
+			for _, sig := range identity.Signatures {
+				if !sig.CheckKeyIdOrFingerprint(signers[0].PrimaryKey) {
+					continue
+				}
+				if err := signers[0].PrimaryKey.VerifyUserIdSignature(identity.Name, keys[0].PrimaryKey, sig); err != nil {
+					return err
+				}
+				if sig.SigType != packet.SigTypeGenericCert {
+					return fmt.Errorf("unexpected signature type %d", sig.SigType)
+				}
+				continue identities
+			}
+			return fmt.Errorf("expected a certification of %q by the old key", identity.Name)
+		}
+		return nil
+	}
+}
+
+// testAccVerifyKeyTransitionStatement checks that the transition statement names the old and the new key and is signed
+// by both. If gpg is installed and the keys are version 4, it also checks that gpg verifies both signatures.
+func testAccVerifyKeyTransitionStatement(statement string, oldPublicKey string, newPublicKey string, home string) error {
+	block, _ := clearsign.Decode([]byte(statement))
+	if block == nil {
+		return fmt.Errorf("transition statement is not clearsigned:\n%s", statement)
+	}
+
+	publicKeys := []string{oldPublicKey, newPublicKey}
+	var fingerprints []string
+	var version int
+	for _, publicKey := range publicKeys {
+		keys, err := openpgp.ReadArmoredKeyRing(strings.NewReader(publicKey))
+		if err != nil {
+			return err
+		}
+		fingerprints = append(fingerprints, fmt.Sprintf("%X", keys[0].PrimaryKey.Fingerprint))
+		version = keys[0].PrimaryKey.Version
+		if _, err := block.VerifySignature(keys, nil); err != nil {
+			return fmt.Errorf("transition statement is not signed by %X: %s", keys[0].PrimaryKey.Fingerprint, err)
+		}
+	}
+	if !strings.Contains(string(block.Plaintext), "Old key: "+fingerprints[0]+"\n") || !strings.Contains(string(block.Plaintext), "New key: "+fingerprints[1]+"\n") {
+		return fmt.Errorf("transition statement does not link %s to %s:\n%s", fingerprints[0], fingerprints[1], block.Plaintext)
+	}
+
+	if _, err := exec.LookPath("gpg"); err != nil || version != 4 {
+		return nil
+	}
+	defer func() {
+		_ = exec.Command("gpgconf", "--homedir", home, "--kill", "gpg-agent").Run()
+	}()
+
+	gpg := func(stdin string, args ...string) (string, error) {
+		cmd := exec.Command("gpg", append([]string{"--homedir", home, "--batch", "--status-fd", "1"}, args...)...)
+		cmd.Stdin = strings.NewReader(stdin)
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("gpg %s failed with error: %s\n%s", strings.Join(args, " "), err, stderr.String())
+		}
+		return string(out), nil
+	}
+	if err := os.MkdirAll(home, 0o700); err != nil {
+		return err
+	}
+	for _, publicKey := range publicKeys {
+		if _, err := gpg(publicKey, "--import"); err != nil {
+			return err
+		}
+	}
+	output, err := gpg(statement, "--verify")
+	if err != nil {
+		return err
+	}
+	for _, fingerprint := range fingerprints {
+		if !strings.Contains(output, "[GNUPG:] VALIDSIG "+fingerprint+" ") {
+			return fmt.Errorf("gpg does not verify the signature of %s:\n%s", fingerprint, output)
+		}
+	}
+	return nil
+}
+
+func testAccKeyTransitionResourceConfig(newKey string, certify bool) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "old" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "old secret"
+}
+
+resource "gpg_key_pair" "new" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "new secret"
+}
+
+resource "gpg_key_transition" "test" {
+  old_private_key = gpg_key_pair.old.private_key
+  old_passphrase  = gpg_key_pair.old.passphrase
+  new_private_key = gpg_key_pair.%[1]s.private_key
+  new_passphrase  = gpg_key_pair.%[1]s.passphrase
+  reason          = "Migration to a new key."
+  certify_new_key = %[2]t
+}
+`, newKey, certify)
+}
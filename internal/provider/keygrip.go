@@ -0,0 +1,346 @@
+package provider
+
+import (
+	"crypto/aes"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/ocb"
+	"github.com/ProtonMail/go-crypto/openpgp/ecdh"
+	"github.com/ProtonMail/go-crypto/openpgp/ed25519"
+	"github.com/ProtonMail/go-crypto/openpgp/eddsa"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/go-crypto/openpgp/s2k"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"github.com/ProtonMail/go-crypto/openpgp/x25519"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// agentS2KCount is the iteration count of the S2K protecting private keys of gpg-agent, the maximum count of OpenPGP.
+const agentS2KCount = 65011712
+
+// agentTimeFormat is the time format of the extended key format of gpg-agent.
+const agentTimeFormat = "20060102T150405"
+
+// keygripCurve holds the curve parameters hashed into the keygrip of an ECC key, as defined by libgcrypt.
+type keygripCurve struct {
+	name  string
+	flags string
+	p     string
+	a     string
+	b     string
+	g     string
+	n     string
+}
+
+// keygripCurves maps the go-crypto curve names to the libgcrypt curve parameters. The parameters are the absolute
+// values, as libgcrypt hashes the magnitude of negative parameters.
+var keygripCurves = map[string]keygripCurve{
+	"ed25519": {
+		name:  "Ed25519",
+		flags: "eddsa",
+		p:     "7FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFED",
+		a:     "01",
+		b:     "2DFC9311D490018C7338BF8688861767FF8FF5B2BEBE27548A14B235ECA6874A",
+		g:     "04216936D3CD6E53FEC0A4E231FDD6DC5C692CC7609525A7B2C9562D608F25D51A6666666666666666666666666666666666666666666666666666666666666658",
+		n:     "1000000000000000000000000000000014DEF9DEA2F79CD65812631A5CF5D3ED",
+	},
+	"curve25519": {
+		name:  "Curve25519",
+		flags: "djb-tweak",
+		p:     "7FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFED",
+		a:     "01DB41",
+		b:     "01",
+		g:     "04000000000000000000000000000000000000000000000000000000000000000920AE19A1B8A086B4E01EDD2C7748D14C923D4D7E6D7C61B229E9C5A27ECED3D9",
+		n:     "1000000000000000000000000000000014DEF9DEA2F79CD65812631A5CF5D3ED",
+	},
+}
+
+// keygrip returns the keygrip of a public key, which GnuPG uses to identify keys independently of the OpenPGP
+// version, e.g. as file name in private-keys-v1.d. RSA, Ed25519 and Curve25519 keys are supported.
+func keygrip(pub *packet.PublicKey) ([]byte, error) {
+	hash := sha1.New()
+	switch key := pub.PublicKey.(type) {
+	case *rsa.PublicKey:
+		hash.Write(stdMPI(key.N.Bytes()))
+	case *eddsa.PublicKey, *ecdh.PublicKey, *ed25519.PublicKey, *x25519.PublicKey:
+		curve, q, err := keygripECCPublic(pub)
+		if err != nil {
+			return nil, err
+		}
+		for _, param := range [][2]string{{"p", curve.p}, {"a", curve.a}, {"b", curve.b}, {"g", curve.g}, {"n", curve.n}} {
+			value, _ := hex.DecodeString(param[1])
+			fmt.Fprintf(hash, "(1:%s%d:", param[0], len(value))
+			hash.Write(value)
+			hash.Write([]byte(")"))
+		}
+		// The keygrip uses the native point without the 0x40 prefix.
+		fmt.Fprintf(hash, "(1:q%d:", len(q)-1)
+		hash.Write(q[1:])
+		hash.Write([]byte(")"))
+	default:
+		return nil, fmt.Errorf("unsupported public key algorithm %d", pub.PubKeyAlgo)
+	}
+	return hash.Sum(nil), nil
+}
+
+// keygrips returns the keygrips of the primary key and the subkeys in upper case hex format.
+func keygrips(primary *packet.PublicKey, subkeys []*packet.PublicKey) ([]string, error) {
+	var grips []string
+	for _, pub := range append([]*packet.PublicKey{primary}, subkeys...) {
+		grip, err := keygrip(pub)
+		if err != nil {
+			return nil, fmt.Errorf("key %X: %w", pub.KeyId, err)
+		}
+		grips = append(grips, strings.ToUpper(hex.EncodeToString(grip)))
+	}
+	return grips, nil
+}
+
+// keygripECCPublic returns the libgcrypt curve and the prefixed point of an ECC public key. The native Ed25519 and
+// X25519 keys of RFC 9580 use the same curves as their legacy counterparts.
+func keygripECCPublic(pub *packet.PublicKey) (keygripCurve, []byte, error) {
+	var name string
+	var q []byte
+	switch key := pub.PublicKey.(type) {
+	case *eddsa.PublicKey:
+		name, q = key.GetCurve().GetCurveName(), key.MarshalPoint()
+	case *ecdh.PublicKey:
+		name, q = key.GetCurve().GetCurveName(), key.MarshalPoint()
+	case *ed25519.PublicKey:
+		name, q = "ed25519", append([]byte{0x40}, key.Point...)
+	case *x25519.PublicKey:
+		name, q = "curve25519", append([]byte{0x40}, key.Point...)
+	}
+	curve, ok := keygripCurves[name]
+	if !ok || len(q) != 33 || q[0] != 0x40 {
+		return keygripCurve{}, nil, fmt.Errorf("unsupported curve %q", name)
+	}
+	return curve, q, nil
+}
+
+// secretKeys returns the primary key and the subkeys of the entity with secret key material. Keys without secret parts,
+// like the GNU dummy primary key of `gpg --export-secret-subkeys`, are skipped.
+func secretKeys(entity *openpgp.Entity) []*openpgp.Key {
+	var keys []*openpgp.Key
+	if entity.PrivateKey != nil && !entity.PrivateKey.Dummy() {
+		keys = append(keys, &openpgp.Key{Entity: entity, PublicKey: entity.PrimaryKey, PrivateKey: entity.PrivateKey})
+	}
+	for i := range entity.Subkeys {
+		subkey := &entity.Subkeys[i]
+		if subkey.PrivateKey != nil && !subkey.PrivateKey.Dummy() {
+			keys = append(keys, &openpgp.Key{Entity: entity, PublicKey: subkey.PublicKey, PrivateKey: subkey.PrivateKey})
+		}
+	}
+	return keys
+}
+
+// agentParam is a parameter of a key S-expression, either a token like a curve name or a binary value.
+type agentParam struct {
+	name  string
+	token string
+	value []byte
+}
+
+// advanced returns the parameter in the advanced S-expression format.
+func (p agentParam) advanced() string {
+	if p.token != "" {
+		return fmt.Sprintf("(%s %s)", p.name, p.token)
+	}
+	return fmt.Sprintf("(%s %s)", p.name, sexpHex(p.value))
+}
+
+// canonical returns the parameter in the canonical S-expression format.
+func (p agentParam) canonical() string {
+	value := p.token
+	if value == "" {
+		value = string(p.value)
+	}
+	return fmt.Sprintf("(%d:%s%d:%s)", len(p.name), p.name, len(value), value)
+}
+
+// agentKeyParams returns the algorithm and the public and secret parameters of an unlocked private key in the order
+// of libgcrypt.
+func agentKeyParams(priv *packet.PrivateKey) (string, []agentParam, []agentParam, error) {
+	switch key := priv.PrivateKey.(type) {
+	case *rsa.PrivateKey:
+		if len(key.Primes) != 2 {
+			return "", nil, nil, fmt.Errorf("unsupported RSA key with %d primes", len(key.Primes))
+		}
+		// libgcrypt expects p < q and u = p^-1 mod q, like OpenPGP.
+		p, q := key.Primes[0], key.Primes[1]
+		if p.Cmp(q) > 0 {
+			p, q = q, p
+		}
+		u := new(big.Int).ModInverse(p, q)
+		public := []agentParam{
+			{name: "n", value: stdMPI(key.N.Bytes())},
+			{name: "e", value: stdMPI(big.NewInt(int64(key.E)).Bytes())},
+		}
+		secret := []agentParam{
+			{name: "d", value: stdMPI(key.D.Bytes())},
+			{name: "p", value: stdMPI(p.Bytes())},
+			{name: "q", value: stdMPI(q.Bytes())},
+			{name: "u", value: stdMPI(u.Bytes())},
+		}
+		return "rsa", public, secret, nil
+	case *eddsa.PrivateKey, *ecdh.PrivateKey, *ed25519.PrivateKey, *x25519.PrivateKey:
+		curve, q, err := keygripECCPublic(&priv.PublicKey)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		var d []byte
+		switch key := key.(type) {
+		case *eddsa.PrivateKey:
+			d = key.MarshalByteSecret()
+		case *ecdh.PrivateKey:
+			d = key.MarshalByteSecret()
+		case *ed25519.PrivateKey:
+			d = key.Seed()
+		case *x25519.PrivateKey:
+			// libgcrypt expects the scalar in big-endian order like the legacy Curve25519 keys.
+			d = make([]byte, len(key.Secret))
+			for i := range key.Secret {
+				d[len(d)-1-i] = key.Secret[i]
+			}
+		}
+		public := []agentParam{
+			{name: "curve", token: curve.name},
+			{name: "flags", token: curve.flags},
+			{name: "q", value: q},
+		}
+		return "ecc", public, []agentParam{{name: "d", value: stdMPI(d)}}, nil
+	default:
+		return "", nil, nil, fmt.Errorf("unsupported public key algorithm %d", priv.PubKeyAlgo)
+	}
+}
+
+// agentPrivateKey returns an unlocked private key in the extended key format of gpg-agent, i.e. the content of
+// private-keys-v1.d/<keygrip>.key. The key is protected with the passphrase like gpg-agent does, using salt and nonce
+// from random, or stored unprotected if the passphrase is empty. The protection time is the creation time of the key, so
+// that the result only depends on random.
+func agentPrivateKey(priv *packet.PrivateKey, passphrase []byte, random io.Reader) (string, error) {
+	if priv.Encrypted {
+		return "", fmt.Errorf("private key %X is locked", priv.KeyId)
+	}
+
+	algorithm, public, secret, err := agentKeyParams(priv)
+	if err != nil {
+		return "", err
+	}
+
+	var advanced strings.Builder
+	for _, param := range public {
+		advanced.WriteString(param.advanced())
+	}
+
+	var sexp string
+	if len(passphrase) == 0 {
+		for _, param := range secret {
+			advanced.WriteString(param.advanced())
+		}
+		sexp = fmt.Sprintf("(private-key (%s %s))", algorithm, advanced.String())
+	} else {
+		protected, err := agentProtect(algorithm, public, secret, passphrase, random, priv.CreationTime)
+		if err != nil {
+			return "", err
+		}
+		sexp = fmt.Sprintf("(protected-private-key (%s %s%s))", algorithm, advanced.String(), protected)
+	}
+
+	return fmt.Sprintf("Created: %s\nKey: %s\n", priv.CreationTime.UTC().Format(agentTimeFormat), wrapSexp(sexp)), nil
+}
+
+// agentProtectionRand returns the source of the salts and nonces protecting the private keys of the key with the
+// fingerprint. It is derived from public data only, as a salt derived from the passphrase would allow testing guessed
+// passphrases without the S2K.
+func agentProtectionRand(fingerprint []byte) io.Reader {
+	return deterministicRand(fingerprint, []byte("gpg-agent key protection"), nil)
+}
+
+// agentProtect encrypts the secret parameters with the openpgp-s2k3-ocb-aes protection of gpg-agent and returns the
+// protected and protected-at parameters. The public parameters and the protection time are authenticated.
+func agentProtect(algorithm string, public []agentParam, secret []agentParam, passphrase []byte, random io.Reader, protectedAt time.Time) (string, error) {
+	salt := make([]byte, 8)
+	nonce := make([]byte, 12)
+	if _, err := io.ReadFull(random, salt); err != nil {
+		return "", err
+	}
+	if _, err := io.ReadFull(random, nonce); err != nil {
+		return "", err
+	}
+
+	key := make([]byte, 16)
+	s2k.Iterated(key, sha1.New(), passphrase, salt, agentS2KCount)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	aead, err := ocb.NewOCBWithNonceAndTagSize(block, len(nonce), 16)
+	if err != nil {
+		return "", err
+	}
+
+	timestamp := protectedAt.UTC().Format(agentTimeFormat)
+
+	var plaintext, additionalData strings.Builder
+	plaintext.WriteString("((")
+	for _, param := range secret {
+		plaintext.WriteString(param.canonical())
+	}
+	plaintext.WriteString("))")
+	fmt.Fprintf(&additionalData, "(%d:%s", len(algorithm), algorithm)
+	for _, param := range public {
+		additionalData.WriteString(param.canonical())
+	}
+	fmt.Fprintf(&additionalData, "(12:protected-at%d:%s))", len(timestamp), timestamp)
+
+	ciphertext := aead.Seal(nil, nonce, []byte(plaintext.String()), []byte(additionalData.String()))
+
+	return fmt.Sprintf("(protected openpgp-s2k3-ocb-aes ((sha1 %s \"%d\")%s)%s)(protected-at \"%s\")",
+		sexpHex(salt), agentS2KCount, sexpHex(nonce), sexpHex(ciphertext), timestamp), nil
+}
+
+// stdMPI returns an unsigned big-endian integer in the signed format of libgcrypt, i.e. without leading zeros but
+// with a zero byte if the most significant bit is set.
+func stdMPI(value []byte) []byte {
+	for len(value) > 0 && value[0] == 0 {
+		value = value[1:]
+	}
+	if len(value) > 0 && value[0]&0x80 != 0 {
+		value = append([]byte{0}, value...)
+	}
+	return value
+}
+
+// sexpHex returns the hex string notation of the advanced S-expression format.
+func sexpHex(value []byte) string {
+	return "#" + strings.ToUpper(hex.EncodeToString(value)) + "#"
+}
+
+// wrapSexp wraps an advanced S-expression into continuation lines of the extended key format. Lines are only broken
+// at spaces and inside hex strings. GnuPG removes the line break and the first space of continuation lines, so spaces
+// separating tokens are kept after the first space.
+func wrapSexp(sexp string) string {
+	const width = 64
+	var b strings.Builder
+	line := 0
+	inHex := false
+	for i, c := range sexp {
+		if c == '#' {
+			inHex = !inHex
+		}
+		breakable := c == ' ' || inHex && c != '#' && sexp[i-1] != '#'
+		if line >= width && breakable {
+			b.WriteString("\n ")
+			line = 1
+		}
+		b.WriteRune(c)
+		line++
+	}
+	return b.String()
+}
@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/ocb"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/go-crypto/openpgp/s2k"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testKeygripRSAKey is an RSA key generated with GnuPG 2.2.40, which reports the keygrip
+// 808C1298C231C312FE3DC20658339ABBCC702475.
+const testKeygripRSAKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mI0EatWlmAEEANA02YALwveFDcaLGFtKMGqy86p4H5Rt1IWU9TUvg+G46rrMfVgE
+MCczJzmG4Nzt3QOFBGY1IsWzxx42M9xENOOEve42NF7tXuN3YNu0YSlkk8rzqQiY
+vLBmDwLw7lTGNj2+0Erv7di6qkKg+ghGrbrT4+xniHyu+YDl/KaXSS3bABEBAAG0
+GlJTQSBUZXN0IDxyc2FAZXhhbXBsZS5jb20+iM4EEwEKADgWIQTvgNPnIcBRJ1Ec
+HHystrmbbz6VawUCatWlmAIbAwULCQgHAgYVCgkICwIEFgIDAQIeAQIXgAAKCRCs
+trmbbz6Vax5JA/sEinanh7js0uIyIfF/f4i51bFh+yphSpOhjZ8tixFymeLEgcYK
+dHYBO4o0r8G+8ouM2gwjNbqbhH0Vrf+gBZqe+S5STicaU14aqmceJgy81b3h3En1
+CBiWVqlp2HDWCoo06N0E3w2a/kapW6624edFYfGoWjd8ZsYAIBXUr2JniA==
+=4B8r
+-----END PGP PUBLIC KEY BLOCK-----`
+
+// testKeygripEd25519Key is an Ed25519 key with a Curve25519 subkey generated with GnuPG 2.2.40, which reports the
+// keygrips C0CE3F7BFBCBFDFE3C5338E2851BACF0D8577536 and 8A97C68D476972EFFFEC14EFE9F214978FC1392E.
+const testKeygripEd25519Key = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatWlmBYJKwYBBAHaRw8BAQdA9DYw+8va8jao/qXnZSlakfFrlAKQRlfH7TmF
+emXJVIW0GEVkIFRlc3QgPGVkQGV4YW1wbGUuY29tPoiQBBMWCAA4FiEEJZCtWZfp
+5b+OJncwVe0gp2RQWgcFAmrVpZgCGwMFCwkIBwIGFQoJCAsCBBYCAwECHgECF4AA
+CgkQVe0gp2RQWgdHRAD+Js7/26kxh2RKV/JReCa4eAJeTaijFOq+JkIzJXNLAfYA
+/0zlu3WnE/rAX5GyO++fPhvncgHEuFrTmDKDmw0OQkgMuDgEatWlmBIKKwYBBAGX
+VQEFAQEHQIN5Zb4fErcRJahOBmPHqfD2iMMHmcS8N/0O7CSQLIV5AwEIB4h4BBgW
+CAAgFiEEJZCtWZfp5b+OJncwVe0gp2RQWgcFAmrVpZgCGwwACgkQVe0gp2RQWge2
+UgD/dEuBfaM+UtqYEI9rxEqr7qk697s2b6GJ8t/teMWb4/kA/20Q7W3FxOjY6YeB
+Ft/lAFedlTi8HEcZD1qWj+ZDJDAI
+=2Seo
+-----END PGP PUBLIC KEY BLOCK-----`
+
+func TestKeygrips(t *testing.T) {
+	for name, test := range map[string]struct {
+		armoredKey string
+		expected   []string
+	}{
+		"rsa": {
+			armoredKey: testKeygripRSAKey,
+			expected:   []string{"808C1298C231C312FE3DC20658339ABBCC702475"},
+		},
+		"ed25519 and curve25519": {
+			armoredKey: testKeygripEd25519Key,
+			expected:   []string{"C0CE3F7BFBCBFDFE3C5338E2851BACF0D8577536", "8A97C68D476972EFFFEC14EFE9F214978FC1392E"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(test.armoredKey))
+			if err != nil {
+				t.Fatal(err)
+			}
+			entity := entities[0]
+			subkeys := make([]*packet.PublicKey, 0, len(entity.Subkeys))
+			for _, subkey := range entity.Subkeys {
+				subkeys = append(subkeys, subkey.PublicKey)
+			}
+			grips, err := keygrips(entity.PrimaryKey, subkeys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(grips, ",") != strings.Join(test.expected, ",") {
+				t.Errorf("expected keygrips %v, got %v", test.expected, grips)
+			}
+		})
+	}
+}
+
+func TestAgentProtect(t *testing.T) {
+	q := append([]byte{0x40}, bytes.Repeat([]byte{0x11}, 32)...)
+	d := bytes.Repeat([]byte{0x22}, 32)
+	public := []agentParam{{name: "curve", token: "Ed25519"}, {name: "flags", token: "eddsa"}, {name: "q", value: q}}
+	secret := []agentParam{{name: "d", value: d}}
+	protectedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	random := bytes.NewReader(bytes.Repeat([]byte{0x33}, 20))
+	protected, err := agentProtect("ecc", public, secret, []byte("top secret"), random, protectedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matches := regexp.MustCompile(`^\(protected openpgp-s2k3-ocb-aes \(\(sha1 #([0-9A-F]{16})# "65011712"\)#([0-9A-F]{24})#\)#([0-9A-F]+)#\)\(protected-at "20240101T000000"\)$`).FindStringSubmatch(protected)
+	if matches == nil {
+		t.Fatalf("unexpected protected parameters %s", protected)
+	}
+	salt, _ := hex.DecodeString(matches[1])
+	nonce, _ := hex.DecodeString(matches[2])
+	ciphertext, _ := hex.DecodeString(matches[3])
+	if !bytes.Equal(salt, bytes.Repeat([]byte{0x33}, 8)) || !bytes.Equal(nonce, bytes.Repeat([]byte{0x33}, 12)) {
+		t.Errorf("salt %X and nonce %X are not read from random", salt, nonce)
+	}
+
+	// gpg-agent authenticates the public parameters and the protection time as canonical S-expression.
+	additionalData := fmt.Sprintf("(3:ecc(5:curve7:Ed25519)(5:flags5:eddsa)(1:q33:%s)(12:protected-at15:20240101T000000))", q)
+	open := func(passphrase string, additionalData string) ([]byte, error) {
+		key := make([]byte, 16)
+		s2k.Iterated(key, sha1.New(), []byte(passphrase), salt, agentS2KCount)
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := ocb.NewOCBWithNonceAndTagSize(block, len(nonce), 16)
+		if err != nil {
+			return nil, err
+		}
+		return aead.Open(nil, nonce, ciphertext, []byte(additionalData))
+	}
+
+	plaintext, err := open("top secret", additionalData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := fmt.Sprintf("(((1:d32:%s)))", d); string(plaintext) != expected {
+		t.Errorf("expected plaintext %q, got %q", expected, plaintext)
+	}
+
+	if _, err := open("wrong secret", additionalData); err == nil {
+		t.Errorf("expected the wrong passphrase to fail")
+	}
+	if _, err := open("top secret", strings.Replace(additionalData, "20240101", "20250101", 1)); err == nil {
+		t.Errorf("expected a changed protection time to fail")
+	}
+}
+
+func TestWrapSexp(t *testing.T) {
+	if wrapped := wrapSexp("(private-key (ecc (curve Ed25519)))"); wrapped != "(private-key (ecc (curve Ed25519)))" {
+		t.Errorf("expected a short S-expression to be kept, got %q", wrapped)
+	}
+
+	sexp := fmt.Sprintf("(protected-private-key (rsa (n %s)(e #010001#)(protected-at \"20240101T000000\")))", sexpHex(bytes.Repeat([]byte{0xab}, 256)))
+	wrapped := wrapSexp(sexp)
+
+	// GnuPG joins continuation lines by removing the line break and the following space.
+	if unwrapped := strings.ReplaceAll(wrapped, "\n ", ""); unwrapped != sexp {
+		t.Errorf("unwrapped S-expression differs:\n%s\n%s", unwrapped, sexp)
+	}
+	lines := strings.Split(wrapped, "\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected a long S-expression to be wrapped, got %q", wrapped)
+	}
+	hexLine := regexp.MustCompile(`^ [0-9A-F]+$`)
+	for i, line := range lines {
+		// Tokens are not broken, but hex strings are.
+		if hexLine.MatchString(line) && len(line) > 65 {
+			t.Errorf("line %d is longer than 65 characters: %q", i+1, line)
+		}
+		if i > 0 && (!strings.HasPrefix(line, " ") || strings.HasPrefix(line, " #")) {
+			t.Errorf("line %d is not a valid continuation line: %q", i+1, line)
+		}
+	}
+}
+
+func TestStdMPI(t *testing.T) {
+	for _, test := range []struct{ value, expected []byte }{
+		{value: []byte{}, expected: []byte{}},
+		{value: []byte{0x00, 0x00, 0x7f}, expected: []byte{0x7f}},
+		{value: []byte{0x80, 0x01}, expected: []byte{0x00, 0x80, 0x01}},
+		{value: []byte{0x00, 0xff}, expected: []byte{0x00, 0xff}},
+	} {
+		if result := stdMPI(test.value); !bytes.Equal(result, test.expected) {
+			t.Errorf("stdMPI(%X) = %X, expected %X", test.value, result, test.expected)
+		}
+	}
+}
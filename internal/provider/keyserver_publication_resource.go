@@ -0,0 +1,366 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"strings"
+	"time"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &KeyserverPublicationResource{}
+var _ resource.ResourceWithConfigure = &KeyserverPublicationResource{}
+var _ resource.ResourceWithValidateConfig = &KeyserverPublicationResource{}
+var _ resource.ResourceWithModifyPlan = &KeyserverPublicationResource{}
+
+func NewKeyserverPublicationResource() resource.Resource {
+	return &KeyserverPublicationResource{}
+}
+
+type KeyserverPublicationResource struct {
+	// defaults holds the provider configuration, it is nil if the provider has not been configured.
+	defaults *GpgProviderModel
+}
+
+func (g *KeyserverPublicationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	defaults, ok := req.ProviderData.(*GpgProviderModel)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *GpgProviderModel, got %T.", req.ProviderData))
+		return
+	}
+	g.defaults = defaults
+}
+
+func (g KeyserverPublicationResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_keyserver_publication"
+}
+
+func (g KeyserverPublicationResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A resource for publishing a public key to an HKP keyserver",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprint of the published key.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"keyserver": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "URL of the keyserver, e.g. `hkps://keyserver.ubuntu.com`. The schemes `hkp`, `hkps`, `http` and `https` are supported. Defaults to the provider's `default_keyserver`.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"public_key": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Public key in armored format. Changing the key uploads it again. A key with another fingerprint, e.g. of a key pair replaced in the same apply, is published in place and the previous key remains published.",
+			},
+			"fingerprint": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprint of the published key.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"server_public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key in armored format as returned by the keyserver during the last refresh.",
+			},
+			"revoked": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the keyserver copy of the key carries a revocation.",
+			},
+			"third_party_signatures": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "Number of signatures on the keyserver copy of the key that were not made by the key itself.",
+			},
+			"in_sync": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the keyserver copy of the key matches `public_key`. This is `false` if the keyserver copy is revoked or carries signatures not present in `public_key`.",
+			},
+		},
+	}
+}
+
+func (g KeyserverPublicationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data keyserverPublicationModelV1
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Keyserver.IsUnknown() && !data.Keyserver.IsNull() {
+		if _, err := hkpBaseURL(data.Keyserver.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("keyserver"),
+				"Invalid keyserver URL",
+				fmt.Sprintf("The keyserver URL is invalid: %s", err),
+			)
+		}
+	}
+}
+
+// ModifyPlan plans the fingerprint of the public key, which is unknown until apply if the public key is. A key with
+// another fingerprint is published by Update rather than by replacing the resource, as Terraform does not allow
+// changing an update to a replacement once the public key becomes known during apply.
+func (g KeyserverPublicationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+
+	var plan keyserverPublicationModelV1
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	fingerprint := types.StringUnknown()
+	if !plan.PublicKey.IsUnknown() {
+		key, err := gpgcrypto.NewKeyFromArmored(plan.PublicKey.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("public_key"), "Invalid public key", fmt.Sprintf("NewKeyFromArmored failed with error: %s", err))
+			return
+		}
+		fingerprint = types.StringValue(key.GetFingerprint())
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("id"), fingerprint)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("fingerprint"), fingerprint)...)
+}
+
+func (g KeyserverPublicationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data keyserverPublicationModelV1
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if g.defaults != nil {
+		data.Keyserver = resolveString(data.Keyserver, g.defaults.DefaultKeyserver, "")
+	}
+	if data.Keyserver.IsNull() || data.Keyserver.IsUnknown() || data.Keyserver.ValueString() == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("keyserver"),
+			"Missing keyserver URL",
+			"Either the resource's keyserver or the provider's default_keyserver must be set.",
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(g.publish(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (g KeyserverPublicationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data keyserverPublicationModelV1
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	serverKey, err := hkpLookup(ctx, data.Keyserver.ValueString(), data.Fingerprint.ValueString())
+	if errors.Is(err, errKeyNotFound) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("GPG keyserver lookup failed", fmt.Sprintf("Lookup of %s failed with error: %s", data.Fingerprint.ValueString(), err))
+		return
+	}
+
+	resp.Diagnostics.Append(data.compare(serverKey)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.InSync.ValueBool() {
+		resp.Diagnostics.AddWarning(
+			"GPG keyserver copy diverges",
+			fmt.Sprintf("The copy of %s on %s is revoked or carries signatures not present in the managed public key.", data.Fingerprint.ValueString(), data.Keyserver.ValueString()),
+		)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update uploads the planned public key again. If the key has another fingerprint, the previous key remains published.
+func (g KeyserverPublicationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state keyserverPublicationModelV1
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(g.publish(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Fingerprint.Equal(state.Fingerprint) {
+		resp.Diagnostics.AddWarning(
+			"GPG key remains published",
+			fmt.Sprintf("The public key was replaced by %s. Keyservers do not support deleting keys, so the previous key %s remains published. Publish a revocation to retire it.", data.Fingerprint.ValueString(), state.Fingerprint.ValueString()),
+		)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete only removes the resource from state, as HKP keyservers do not support deleting keys.
+func (g KeyserverPublicationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.AddWarning(
+		"GPG key remains published",
+		"Keyservers do not support deleting keys. The key was only removed from the Terraform state. Publish a revocation to retire it.",
+	)
+}
+
+// publish uploads the public key of the model to its keyserver and refreshes the computed attributes.
+func (g KeyserverPublicationResource) publish(ctx context.Context, data *keyserverPublicationModelV1) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	key, err := gpgcrypto.NewKeyFromArmored(data.PublicKey.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("public_key"), "GPG keyserver publication failed", fmt.Sprintf("NewKeyFromArmored failed with error: %s", err))
+		return diags
+	}
+	if key.IsPrivate() {
+		diags.AddAttributeError(path.Root("public_key"), "GPG keyserver publication failed", "The key contains private key material and must not be published.")
+		return diags
+	}
+
+	data.Id = types.StringValue(key.GetFingerprint())
+	data.Fingerprint = types.StringValue(key.GetFingerprint())
+
+	if err := hkpAdd(ctx, data.Keyserver.ValueString(), data.PublicKey.ValueString()); err != nil {
+		diags.AddError("GPG keyserver publication failed", fmt.Sprintf("Upload of %s failed with error: %s", data.Fingerprint.ValueString(), err))
+		return diags
+	}
+
+	serverKey, err := hkpLookup(ctx, data.Keyserver.ValueString(), data.Fingerprint.ValueString())
+	if err != nil {
+		diags.AddError("GPG keyserver lookup failed", fmt.Sprintf("Lookup of %s failed with error: %s", data.Fingerprint.ValueString(), err))
+		return diags
+	}
+
+	diags.Append(data.compare(serverKey)...)
+	return diags
+}
+
+type keyserverPublicationModelV1 struct {
+	Id                   types.String `tfsdk:"id"`
+	Keyserver            types.String `tfsdk:"keyserver"`
+	PublicKey            types.String `tfsdk:"public_key"`
+	Fingerprint          types.String `tfsdk:"fingerprint"`
+	ServerPublicKey      types.String `tfsdk:"server_public_key"`
+	Revoked              types.Bool   `tfsdk:"revoked"`
+	ThirdPartySignatures types.Int64  `tfsdk:"third_party_signatures"`
+	InSync               types.Bool   `tfsdk:"in_sync"`
+}
+
+// compare sets the keyserver related attributes of the model from the armored key returned by the keyserver.
+func (m *keyserverPublicationModelV1) compare(serverKey string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	local, err := openpgp.ReadArmoredKeyRing(strings.NewReader(m.PublicKey.ValueString()))
+	if err != nil {
+		diags.AddError("GPG keyserver comparison failed", fmt.Sprintf("ReadArmoredKeyRing failed for the managed key with error: %s", err))
+		return diags
+	}
+	remote, err := openpgp.ReadArmoredKeyRing(strings.NewReader(serverKey))
+	if err != nil {
+		diags.AddError("GPG keyserver comparison failed", fmt.Sprintf("ReadArmoredKeyRing failed for the keyserver copy with error: %s", err))
+		return diags
+	}
+	if len(local) != 1 || len(remote) != 1 {
+		diags.AddError("GPG keyserver comparison failed", fmt.Sprintf("Expected exactly one key, got %d managed and %d from the keyserver.", len(local), len(remote)))
+		return diags
+	}
+
+	known := make(map[string]bool)
+	for _, sig := range entitySignatures(local[0]) {
+		known[serializedSignature(sig)] = true
+	}
+
+	diverged := false
+	thirdParty := 0
+	for _, sig := range entitySignatures(remote[0]) {
+		if !known[serializedSignature(sig)] {
+			diverged = true
+		}
+		if !sig.CheckKeyIdOrFingerprint(remote[0].PrimaryKey) {
+			thirdParty++
+		}
+	}
+	revoked := remote[0].Revoked(time.Now())
+
+	m.ServerPublicKey = types.StringValue(serverKey)
+	m.Revoked = types.BoolValue(revoked)
+	m.ThirdPartySignatures = types.Int64Value(int64(thirdParty))
+	m.InSync = types.BoolValue(!revoked && !diverged)
+	return diags
+}
+
+// entitySignatures returns all signatures attached to the primary key, its identities and its subkeys.
+func entitySignatures(e *openpgp.Entity) []*packet.Signature {
+	sigs := append([]*packet.Signature{}, e.Revocations...)
+	sigs = append(sigs, e.Signatures...)
+	for _, identity := range e.Identities {
+		sigs = append(sigs, identity.Signatures...)
+	}
+	for _, subkey := range e.Subkeys {
+		sigs = append(sigs, subkey.Revocations...)
+		if subkey.Sig != nil {
+			sigs = append(sigs, subkey.Sig)
+		}
+	}
+	return sigs
+}
+
+// serializedSignature returns the serialized signature packet, which identifies the signature.
+func serializedSignature(sig *packet.Signature) string {
+	var buf bytes.Buffer
+	if err := sig.Serialize(&buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf.Bytes())
+}
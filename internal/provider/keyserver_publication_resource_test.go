@@ -0,0 +1,275 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestAccKeyserverPublicationResource(t *testing.T) {
+	keyserver := newTestKeyserver()
+	defer keyserver.Close()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccKeyserverPublicationResourceConfig(keyserver.URL, "John Doe"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("gpg_keyserver_publication.test", "fingerprint", "gpg_key_pair.test", "fingerprint"),
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "in_sync", "true"),
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "revoked", "false"),
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "third_party_signatures", "0"),
+				),
+			},
+			// The key pair is replaced, so the fingerprint is only known after apply.
+			{
+				Config: testAccKeyserverPublicationResourceConfig(keyserver.URL, "Jane Doe"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_key_pair.test", plancheck.ResourceActionReplace),
+						plancheck.ExpectResourceAction("gpg_keyserver_publication.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectUnknownValue("gpg_keyserver_publication.test", tfjsonpath.New("fingerprint")),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("gpg_keyserver_publication.test", "fingerprint", "gpg_key_pair.test", "fingerprint"),
+					resource.TestCheckResourceAttrPair("gpg_keyserver_publication.test", "id", "gpg_key_pair.test", "fingerprint"),
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "in_sync", "true"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccKeyserverPublicationResource_providerDefault(t *testing.T) {
+	keyserver := newTestKeyserver()
+	defer keyserver.Close()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccKeyserverPublicationResourceProviderDefaultConfig(keyserver.URL),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "keyserver", keyserver.URL),
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "in_sync", "true"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccKeyserverPublicationResource_divergence(t *testing.T) {
+	keyserver := newTestKeyserver()
+	defer keyserver.Close()
+
+	key := testAccNewEntity(t, "John Doe", "john.doe@example.com")
+	certifier := testAccNewEntity(t, "Jane Doe", "jane.doe@example.com")
+	otherKey := testAccNewEntity(t, "John Doe", "john.doe@example.com")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccKeyserverPublicationResourcePublicKeyConfig(keyserver.URL, testAccArmorPublicKey(t, key)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "in_sync", "true"),
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "third_party_signatures", "0"),
+				),
+			},
+			// The keyserver merged a certification by another key.
+			{
+				PreConfig: func() {
+					certified := testAccCopyEntity(t, key)
+					if err := certified.SignIdentity("John Doe <john.doe@example.com>", certifier, nil); err != nil {
+						t.Fatal(err)
+					}
+					keyserver.set(fmt.Sprintf("%X", key.PrimaryKey.Fingerprint), testAccArmorPublicKey(t, certified))
+				},
+				Config: testAccKeyserverPublicationResourcePublicKeyConfig(keyserver.URL, testAccArmorPublicKey(t, key)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "in_sync", "false"),
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "revoked", "false"),
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "third_party_signatures", "1"),
+				),
+			},
+			// The keyserver received a revocation of the key.
+			{
+				PreConfig: func() {
+					revoked := testAccCopyEntity(t, key)
+					if err := revoked.RevokeKey(packet.KeyCompromised, "compromised", nil); err != nil {
+						t.Fatal(err)
+					}
+					keyserver.set(fmt.Sprintf("%X", key.PrimaryKey.Fingerprint), testAccArmorPublicKey(t, revoked))
+				},
+				Config: testAccKeyserverPublicationResourcePublicKeyConfig(keyserver.URL, testAccArmorPublicKey(t, key)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "in_sync", "false"),
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "revoked", "true"),
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "third_party_signatures", "0"),
+				),
+			},
+			// A key with another fingerprint is published in place.
+			{
+				Config: testAccKeyserverPublicationResourcePublicKeyConfig(keyserver.URL, testAccArmorPublicKey(t, otherKey)),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_keyserver_publication.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectKnownValue("gpg_keyserver_publication.test", tfjsonpath.New("fingerprint"), knownvalue.StringExact(fmt.Sprintf("%x", otherKey.PrimaryKey.Fingerprint))),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "fingerprint", fmt.Sprintf("%x", otherKey.PrimaryKey.Fingerprint)),
+					resource.TestCheckResourceAttr("gpg_keyserver_publication.test", "in_sync", "true"),
+				),
+			},
+		},
+	})
+}
+
+// testAccNewEntity generates a key with the user ID.
+func testAccNewEntity(t *testing.T, name string, email string) *openpgp.Entity {
+	entity, err := openpgp.NewEntity(name, "", email, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entity
+}
+
+// testAccCopyEntity returns a copy of the key that can be modified without affecting the key.
+func testAccCopyEntity(t *testing.T, entity *openpgp.Entity) *openpgp.Entity {
+	var buf bytes.Buffer
+	if err := entity.SerializePrivateWithoutSigning(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	copied, err := openpgp.ReadEntity(packet.NewReader(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return copied
+}
+
+// testAccArmorPublicKey returns the public key of the key in armored format.
+func testAccArmorPublicKey(t *testing.T, entity *openpgp.Entity) string {
+	var buf strings.Builder
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+// testKeyserver is a minimal in-memory HKP keyserver.
+type testKeyserver struct {
+	*httptest.Server
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+func newTestKeyserver() *testKeyserver {
+	ks := &testKeyserver{keys: make(map[string]string)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pks/add", func(w http.ResponseWriter, r *http.Request) {
+		key, err := crypto.NewKeyFromArmored(r.FormValue("keytext"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ks.mu.Lock()
+		defer ks.mu.Unlock()
+		ks.keys[strings.ToUpper(key.GetFingerprint())] = r.FormValue("keytext")
+	})
+	mux.HandleFunc("/pks/lookup", func(w http.ResponseWriter, r *http.Request) {
+		ks.mu.Lock()
+		defer ks.mu.Unlock()
+		key, ok := ks.keys[strings.TrimPrefix(r.FormValue("search"), "0x")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = fmt.Fprint(w, key)
+	})
+	ks.Server = httptest.NewServer(mux)
+	return ks
+}
+
+// set replaces the copy of the key with the fingerprint in upper case hex format, e.g. with a copy that other users
+// signed or revoked.
+func (ks *testKeyserver) set(fingerprint string, armoredKey string) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys[fingerprint] = armoredKey
+}
+
+func testAccKeyserverPublicationResourceConfig(keyserver string, name string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = %[2]q
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+}
+
+resource "gpg_keyserver_publication" "test" {
+  keyserver  = %[1]q
+  public_key = gpg_key_pair.test.public_key
+}
+`, keyserver, name)
+}
+
+func testAccKeyserverPublicationResourceProviderDefaultConfig(keyserver string) string {
+	return fmt.Sprintf(`
+provider "gpg" {
+  default_keyserver = %[1]q
+}
+
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+}
+
+resource "gpg_keyserver_publication" "test" {
+  public_key = gpg_key_pair.test.public_key
+}
+`, keyserver)
+}
+
+func testAccKeyserverPublicationResourcePublicKeyConfig(keyserver string, publicKey string) string {
+	return fmt.Sprintf(`
+resource "gpg_keyserver_publication" "test" {
+  keyserver  = %[1]q
+  public_key = %[2]q
+}
+`, keyserver, publicKey)
+}
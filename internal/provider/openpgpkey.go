@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// openpgpkeyRecordAttrTypes are the attribute types of openpgpkeyRecordModelV1.
+var openpgpkeyRecordAttrTypes = map[string]attr.Type{
+	"email": types.StringType,
+	"name":  types.StringType,
+	"rdata": types.StringType,
+}
+
+type openpgpkeyRecordModelV1 struct {
+	Email types.String `tfsdk:"email"`
+	Name  types.String `tfsdk:"name"`
+	Rdata types.String `tfsdk:"rdata"`
+}
+
+// publishableEmails returns the sorted unique email addresses of the user IDs of the key. User IDs without a valid
+// self-signature, e.g. due to a critical notation, cannot be published.
+func publishableEmails(entity *openpgp.Entity) []string {
+	emails := make([]string, 0, len(entity.Identities))
+	for _, identity := range entity.Identities {
+		if identity.UserId.Email == "" || slices.Contains(emails, identity.UserId.Email) {
+			continue
+		}
+		if _, err := identity.LatestValidSelfCertification(time.Time{}, nil); err == nil {
+			emails = append(emails, identity.UserId.Email)
+		}
+	}
+	slices.Sort(emails)
+	return emails
+}
+
+// openpgpkeyRecord returns the RFC 7929 OPENPGPKEY record publishing the key for the email address. Like GnuPG, the
+// local part is lowercased before it is hashed.
+func openpgpkeyRecord(entity *openpgp.Entity, email string) (*openpgpkeyRecordModelV1, error) {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 {
+		return nil, fmt.Errorf("invalid email address %q", email)
+	}
+	hash := sha256.Sum256([]byte(strings.ToLower(email[:at])))
+
+	minimal, err := minimalPublicKey(entity, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openpgpkeyRecordModelV1{
+		Email: types.StringValue(email),
+		Name:  types.StringValue(hex.EncodeToString(hash[:28]) + "._openpgpkey." + strings.ToLower(email[at+1:])),
+		Rdata: types.StringValue(base64.StdEncoding.EncodeToString(minimal)),
+	}, nil
+}
+
+// minimalPublicKey serializes the public key with only the user IDs of the email address, like
+// `gpg --export-options export-minimal --export-filter keep-uid=mbox=<email>`. Only the latest self-signature of each
+// user ID and binding signature of each subkey are kept, while certifications by other keys are dropped.
+func minimalPublicKey(entity *openpgp.Entity, email string) ([]byte, error) {
+	minimal := &openpgp.Entity{
+		PrimaryKey:       entity.PrimaryKey,
+		Identities:       map[string]*openpgp.Identity{},
+		Revocations:      entity.Revocations,
+		DirectSignatures: entity.DirectSignatures,
+	}
+	for name, identity := range entity.Identities {
+		if !strings.EqualFold(identity.UserId.Email, email) {
+			continue
+		}
+		sig, err := identity.LatestValidSelfCertification(time.Time{}, nil)
+		if err != nil {
+			continue
+		}
+		minimal.Identities[name] = &openpgp.Identity{
+			Primary:            minimal,
+			Name:               identity.Name,
+			UserId:             identity.UserId,
+			SelfCertifications: []*packet.VerifiableSignature{packet.NewVerifiableSig(sig)},
+			Revocations:        identity.Revocations,
+		}
+	}
+	if len(minimal.Identities) == 0 {
+		return nil, fmt.Errorf("the key has no valid user ID with email %q", email)
+	}
+	for _, subkey := range entity.Subkeys {
+		sig, err := subkey.LatestValidBindingSignature(time.Time{}, nil)
+		if err != nil {
+			continue
+		}
+		minimal.Subkeys = append(minimal.Subkeys, openpgp.Subkey{
+			Primary:     minimal,
+			PublicKey:   subkey.PublicKey,
+			Bindings:    []*packet.VerifiableSignature{packet.NewVerifiableSig(sig)},
+			Revocations: subkey.Revocations,
+		})
+	}
+
+	// Entity.Serialize writes the user IDs in map order, so they are written sorted to keep the record stable.
+	var buf bytes.Buffer
+	if err := minimal.PrimaryKey.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	for _, sig := range slices.Concat(minimal.Revocations, minimal.DirectSignatures) {
+		if err := sig.Packet.Serialize(&buf); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(minimal.Identities)) {
+		if err := minimal.Identities[name].Serialize(&buf); err != nil {
+			return nil, err
+		}
+	}
+	for _, subkey := range minimal.Subkeys {
+		if err := subkey.Serialize(&buf, false); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &OpenpgpkeyRecordFunction{}
+
+func NewOpenpgpkeyRecordFunction() function.Function {
+	return &OpenpgpkeyRecordFunction{}
+}
+
+type OpenpgpkeyRecordFunction struct{}
+
+func (f OpenpgpkeyRecordFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "openpgpkey_record"
+}
+
+func (f OpenpgpkeyRecordFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Convert a GPG key to an OPENPGPKEY DNS record",
+		MarkdownDescription: "Returns the RFC 7929 OPENPGPKEY record publishing the key for an email address. The owner `name` is " +
+			"the hex-encoded SHA-256 hash of the local part truncated to 28 octets followed by `._openpgpkey.` and the domain, " +
+			"where the local part is lowercased like GnuPG does. The `rdata` is the minimal public key in base64 format, with " +
+			"only the user IDs of the email address, their latest self-signature and the latest binding signature of each subkey.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "public_key",
+				MarkdownDescription: "Public or private key in armored format, only the public key is published.",
+			},
+			function.StringParameter{
+				Name:                "email",
+				MarkdownDescription: "Email address of a user ID of the key.",
+			},
+		},
+		Return: function.ObjectReturn{AttributeTypes: openpgpkeyRecordAttrTypes},
+	}
+}
+
+func (f OpenpgpkeyRecordFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var armoredKey, email string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &armoredKey, &email))
+
+	if resp.Error != nil {
+		return
+	}
+
+	key, err := gpgcrypto.NewKeyFromArmored(armoredKey)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("NewKeyFromArmored failed with error: %s", err))
+		return
+	}
+
+	record, err := openpgpkeyRecord(key.GetEntity(), email)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("Generating the record failed with error: %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, record))
+}
@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccOpenpgpkeyRecordFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOpenpgpkeyRecordFunctionConfig("gnupg", "John.Doe@example.com"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("name", "30f69670bba25e88a97e749a67b8b93db6541ebd309094e5ad0e3c56._openpgpkey.example.com"),
+					testAccCheckOutputEqualsAttr("name", "gpg_key_pair.test", "openpgpkey_records.0.name"),
+					testAccCheckOutputEqualsAttr("rdata", "gpg_key_pair.test", "openpgpkey_records.0.rdata"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "openpgpkey_records.#", "1"),
+					resource.TestCheckResourceAttr("gpg_key_pair.test", "openpgpkey_records.0.email", "john.doe@example.com"),
+					testAccCheckGpgOpenpgpkeyRecord("gpg_key_pair.test", "openpgpkey_records.0", filepath.Join(t.TempDir(), "gnupg")),
+				),
+			},
+			{
+				Config: testAccOpenpgpkeyRecordFunctionConfig("rfc9580", "john.doe@example.com"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckOutputEqualsAttr("rdata", "gpg_key_pair.test", "openpgpkey_records.0.rdata"),
+					testAccCheckGpgOpenpgpkeyRecord("gpg_key_pair.test", "openpgpkey_records.0", ""),
+				),
+			},
+			{
+				Config:      testAccOpenpgpkeyRecordFunctionConfig("gnupg", "jane.doe@example.com"),
+				ExpectError: regexp.MustCompile(`no valid user ID with email`),
+			},
+		},
+	})
+}
+
+// testAccCheckGpgOpenpgpkeyRecord checks that the record data is the public key of the key pair with only the user ID
+// of the record's email. If gpg is installed and the key is version 4, it also checks that gpg exports the same record.
+func testAccCheckGpgOpenpgpkeyRecord(name string, record string, home string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+		email := rs.Primary.Attributes[record+".email"]
+		owner := rs.Primary.Attributes[record+".name"]
+
+		rdata, err := base64.StdEncoding.DecodeString(rs.Primary.Attributes[record+".rdata"])
+		if err != nil {
+			return err
+		}
+		keys, err := openpgp.ReadKeyRing(bytes.NewReader(rdata))
+		if err != nil {
+			return err
+		}
+		if len(keys) != 1 || keys[0].PrivateKey != nil || fmt.Sprintf("%x", keys[0].PrimaryKey.Fingerprint) != rs.Primary.Attributes["fingerprint"] {
+			return fmt.Errorf("expected the public key %s", rs.Primary.Attributes["fingerprint"])
+		}
+		if len(keys[0].Identities) != 1 {
+			return fmt.Errorf("expected only the user ID of %s, got %d user IDs", email, len(keys[0].Identities))
+		}
+		for _, identity := range keys[0].Identities {
+			if identity.UserId.Email != email {
+				return fmt.Errorf("expected the user ID of %s, got %s", email, identity.Name)
+			}
+		}
+
+		if _, err := exec.LookPath("gpg"); err != nil || keys[0].PrimaryKey.Version != 4 {
+			return nil
+		}
+		defer func() {
+			_ = exec.Command("gpgconf", "--homedir", home, "--kill", "gpg-agent").Run()
+		}()
+
+		gpg := func(stdin string, args ...string) (string, error) {
+			cmd := exec.Command("gpg", append([]string{"--homedir", home, "--batch"}, args...)...)
+			cmd.Stdin = strings.NewReader(stdin)
+			var stderr strings.Builder
+			cmd.Stderr = &stderr
+			out, err := cmd.Output()
+			if err != nil {
+				return "", fmt.Errorf("gpg %s failed with error: %s\n%s", strings.Join(args, " "), err, stderr.String())
+			}
+			return string(out), nil
+		}
+		if err := os.MkdirAll(home, 0o700); err != nil {
+			return err
+		}
+		if _, err := gpg(rs.Primary.Attributes["public_key"], "--import"); err != nil {
+			return err
+		}
+		output, err := gpg("", "--export-options", "export-dane,export-minimal", "--export-filter", "keep-uid=mbox="+email, "--export", rs.Primary.Attributes["fingerprint"])
+		if err != nil {
+			return err
+		}
+
+		// gpg prints the record relative to the origin with the record data as hex lines in parentheses.
+		match := regexp.MustCompile(`(?s)\$ORIGIN (\S+)\.\n.*?\n([0-9a-f]{56}) TYPE61 \\# \d+ \((.*?)\)`).FindStringSubmatch(output)
+		if match == nil {
+			return fmt.Errorf("unexpected output of gpg:\n%s", output)
+		}
+		if expected := match[2] + "." + match[1]; owner != expected {
+			return fmt.Errorf("expected owner name %s, got %s", expected, owner)
+		}
+		expected, err := hex.DecodeString(strings.Join(strings.Fields(match[3]), ""))
+		if err != nil {
+			return err
+		}
+		// gpg writes old format packet headers for version 4 keys, so only the packet tags and bodies are compared.
+		bodies, err := packetBodies(rdata)
+		if err != nil {
+			return err
+		}
+		expectedBodies, err := packetBodies(expected)
+		if err != nil {
+			return err
+		}
+		if len(bodies) != len(expectedBodies) {
+			return fmt.Errorf("record data has %d packets, gpg exports %d packets", len(bodies), len(expectedBodies))
+		}
+		for i, body := range bodies {
+			expectedBody := expectedBodies[i]
+			if body.tag != expectedBody.tag || !bytes.Equal(rdata[body.offset:body.offset+body.length], expected[expectedBody.offset:expectedBody.offset+expectedBody.length]) {
+				return fmt.Errorf("record data differs from gpg in packet %d:\n%x\n%x", i, rdata, expected)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccOpenpgpkeyRecordFunctionConfig(profile string, email string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+  profile    = %[1]q
+}
+
+locals {
+  record = provider::gpg::openpgpkey_record(gpg_key_pair.test.public_key, %[2]q)
+}
+
+output "name" {
+  value = local.record.name
+}
+
+output "rdata" {
+  value = local.record.rdata
+}
+`, profile, email)
+}
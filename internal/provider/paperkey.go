@@ -0,0 +1,341 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"strconv"
+	"strings"
+)
+
+const (
+	// paperkeyVersion is the version of the paperkey format.
+	paperkeyVersion = 0
+	// paperkeyLineItems is the number of bytes per line, the default of paperkey for a width of 78 characters.
+	paperkeyLineItems = 22
+
+	packetTagSecretKey    = 5
+	packetTagPublicKey    = 6
+	packetTagSecretSubkey = 7
+	packetTagPublicSubkey = 14
+
+	crc24Init = 0xb704ce
+	crc24Poly = 0x1864cfb
+)
+
+// errPaperkeyVersion is returned for keys other than version 4, which the paperkey format does not support.
+var errPaperkeyVersion = errors.New("paperkey only supports version 4 keys")
+
+// paperkeyHeader describes the format in the same way as paperkey does, so that a printed key can be restored
+// without this provider.
+const paperkeyHeader = `#
+# File format:
+# a) 1 octet:  Version of the paperkey format (currently 0).
+# b) 1 octet:  OpenPGP key or subkey version (currently 4)
+# c) n octets: Key fingerprint (20 octets for a version 4 key or subkey)
+# d) 2 octets: 16-bit big endian length of the following secret data
+# e) n octets: Secret data: a partial OpenPGP secret key or subkey packet as
+#              specified in RFC 4880, starting with the string-to-key usage
+#              octet and continuing until the end of the packet.
+# Repeat fields b through e as needed to cover all subkeys.
+#
+# To recover a secret key without using the paperkey program, use the
+# key fingerprint to match an existing public key packet with the
+# corresponding secret data from the paper key.  Next, append this secret
+# data to the public key packet.  Finally, switch the public key packet tag
+# from 6 to 5 (14 to 7 for subkeys).  This will recreate the original secret
+# key or secret subkey packet.  Repeat as needed for all public key or subkey
+# packets in the public key.  All other packets (user IDs, signatures, etc.)
+# may simply be copied from the public key.
+#
+# Each base16 line ends with a CRC-24 of that line.
+# The entire block of data ends with a CRC-24 of the entire block of data.
+
+`
+
+// crc24 updates the OpenPGP CRC-24 checksum with data.
+func crc24(crc uint32, data []byte) uint32 {
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= crc24Poly
+			}
+		}
+	}
+	return crc & 0xffffff
+}
+
+// rawPacket is an OpenPGP packet split into tag and body.
+type rawPacket struct {
+	tag  uint8
+	body []byte
+}
+
+// readPackets splits a binary OpenPGP message into its packets. Partial body lengths are not supported, as they are
+// not used in keys.
+func readPackets(data []byte) ([]rawPacket, error) {
+	var packets []rawPacket
+	for len(data) > 0 {
+		header := data[0]
+		if header&0x80 == 0 {
+			return nil, errors.New("invalid packet header")
+		}
+		var tag uint8
+		var length, offset int
+		if header&0x40 != 0 {
+			tag = header & 0x3f
+			switch {
+			case len(data) < 2:
+				return nil, errors.New("truncated packet header")
+			case data[1] < 192:
+				length, offset = int(data[1]), 2
+			case data[1] < 224:
+				if len(data) < 3 {
+					return nil, errors.New("truncated packet header")
+				}
+				length, offset = (int(data[1])-192)<<8+int(data[2])+192, 3
+			case data[1] == 255:
+				if len(data) < 6 {
+					return nil, errors.New("truncated packet header")
+				}
+				length, offset = int(binary.BigEndian.Uint32(data[2:6])), 6
+			default:
+				return nil, errors.New("partial body lengths are not supported")
+			}
+		} else {
+			tag = (header & 0x3f) >> 2
+			switch header & 3 {
+			case 0:
+				if len(data) < 2 {
+					return nil, errors.New("truncated packet header")
+				}
+				length, offset = int(data[1]), 2
+			case 1:
+				if len(data) < 3 {
+					return nil, errors.New("truncated packet header")
+				}
+				length, offset = int(binary.BigEndian.Uint16(data[1:3])), 3
+			case 2:
+				if len(data) < 5 {
+					return nil, errors.New("truncated packet header")
+				}
+				length, offset = int(binary.BigEndian.Uint32(data[1:5])), 5
+			default:
+				return nil, errors.New("indeterminate packet lengths are not supported")
+			}
+		}
+		if len(data) < offset+length {
+			return nil, errors.New("truncated packet body")
+		}
+		packets = append(packets, rawPacket{tag: tag, body: data[offset : offset+length]})
+		data = data[offset+length:]
+	}
+	return packets, nil
+}
+
+// serialize writes the packet with a new format header.
+func (p rawPacket) serialize(buf *bytes.Buffer) {
+	buf.WriteByte(0xc0 | p.tag)
+	switch length := len(p.body); {
+	case length < 192:
+		buf.WriteByte(byte(length))
+	case length < 8384:
+		length -= 192
+		buf.WriteByte(byte(length>>8) + 192)
+		buf.WriteByte(byte(length))
+	default:
+		buf.WriteByte(255)
+		_ = binary.Write(buf, binary.BigEndian, uint32(length))
+	}
+	buf.Write(p.body)
+}
+
+// toPaperkey extracts the secret parts of a binary secret key in the paperkey text format.
+func toPaperkey(secretKey []byte) (string, error) {
+	packets, err := readPackets(secretKey)
+	if err != nil {
+		return "", err
+	}
+
+	data := []byte{paperkeyVersion}
+	var primaryFingerprint []byte
+	for _, p := range packets {
+		if p.tag != packetTagSecretKey && p.tag != packetTagSecretSubkey {
+			continue
+		}
+		parsed, err := packet.Read(bytes.NewReader(serializedPacket(p)))
+		if err != nil {
+			return "", err
+		}
+		privateKey, ok := parsed.(*packet.PrivateKey)
+		if !ok {
+			return "", errors.New("not a secret key packet")
+		}
+		if privateKey.Version != 4 {
+			return "", errPaperkeyVersion
+		}
+		var public bytes.Buffer
+		if err := privateKey.PublicKey.Serialize(&public); err != nil {
+			return "", err
+		}
+		publicPackets, err := readPackets(public.Bytes())
+		if err != nil {
+			return "", err
+		}
+		secret := p.body[len(publicPackets[0].body):]
+		if len(secret) > 0xffff {
+			return "", errors.New("secret key data is too long")
+		}
+
+		if primaryFingerprint == nil {
+			primaryFingerprint = privateKey.Fingerprint
+		}
+		data = append(data, byte(privateKey.Version))
+		data = append(data, privateKey.Fingerprint...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(secret)))
+		data = append(data, secret...)
+	}
+	if primaryFingerprint == nil {
+		return "", errors.New("no secret key found")
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "# Secret portions of key %X\n", primaryFingerprint)
+	out.WriteString(paperkeyHeader)
+
+	line := 1
+	for offset := 0; offset < len(data); offset += paperkeyLineItems {
+		chunk := data[offset:min(offset+paperkeyLineItems, len(data))]
+		fmt.Fprintf(&out, "%3d: ", line)
+		for _, b := range chunk {
+			fmt.Fprintf(&out, "%02X ", b)
+		}
+		fmt.Fprintf(&out, "%06X\n", crc24(crc24Init, chunk))
+		line++
+	}
+	fmt.Fprintf(&out, "%3d: %06X\n", line, crc24(crc24Init, data))
+	return out.String(), nil
+}
+
+// serializedPacket returns the packet with a new format header.
+func serializedPacket(p rawPacket) []byte {
+	var buf bytes.Buffer
+	p.serialize(&buf)
+	return buf.Bytes()
+}
+
+// fromPaperkey restores a binary secret key from the paperkey text format and the binary public key.
+func fromPaperkey(text string, publicKey []byte) ([]byte, error) {
+	var data []byte
+	var checksum uint32
+	checksumFound := false
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if checksumFound {
+			return nil, errors.New("unexpected data after the checksum line")
+		}
+		number, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid line %q", line)
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("line %s is empty", number)
+		}
+		lineChecksum, err := strconv.ParseUint(fields[len(fields)-1], 16, 32)
+		if err != nil || len(fields[len(fields)-1]) != 6 {
+			return nil, fmt.Errorf("line %s has an invalid checksum", number)
+		}
+		if len(fields) == 1 {
+			checksum, checksumFound = uint32(lineChecksum), true
+			continue
+		}
+		chunk, err := hex.DecodeString(strings.Join(fields[:len(fields)-1], ""))
+		if err != nil {
+			return nil, fmt.Errorf("line %s is not valid base16: %w", number, err)
+		}
+		if crc24(crc24Init, chunk) != uint32(lineChecksum) {
+			return nil, fmt.Errorf("line %s has a checksum mismatch", number)
+		}
+		data = append(data, chunk...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !checksumFound {
+		return nil, errors.New("missing checksum line")
+	}
+	if crc24(crc24Init, data) != checksum {
+		return nil, errors.New("checksum mismatch of the entire data")
+	}
+
+	if len(data) == 0 || data[0] != paperkeyVersion {
+		return nil, errors.New("unsupported paperkey format version")
+	}
+	secrets := make(map[string][]byte)
+	for rest := data[1:]; len(rest) > 0; {
+		if rest[0] != 4 {
+			return nil, errPaperkeyVersion
+		}
+		if len(rest) < 23 {
+			return nil, errors.New("truncated paperkey data")
+		}
+		fingerprint := rest[1:21]
+		length := int(binary.BigEndian.Uint16(rest[21:23]))
+		if len(rest) < 23+length {
+			return nil, errors.New("truncated paperkey data")
+		}
+		secrets[hex.EncodeToString(fingerprint)] = rest[23 : 23+length]
+		rest = rest[23+length:]
+	}
+
+	packets, err := readPackets(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	for _, p := range packets {
+		if p.tag == packetTagPublicKey || p.tag == packetTagPublicSubkey {
+			parsed, err := packet.Read(bytes.NewReader(serializedPacket(p)))
+			if err != nil {
+				return nil, err
+			}
+			pub, ok := parsed.(*packet.PublicKey)
+			if !ok {
+				return nil, errors.New("not a public key packet")
+			}
+			secret, ok := secrets[hex.EncodeToString(pub.Fingerprint)]
+			if !ok {
+				if p.tag == packetTagPublicKey {
+					return nil, fmt.Errorf("paperkey does not contain the secret key %X", pub.Fingerprint)
+				}
+				// Subkeys added after printing are kept public.
+				p.serialize(&out)
+				continue
+			}
+			delete(secrets, hex.EncodeToString(pub.Fingerprint))
+			tag := uint8(packetTagSecretKey)
+			if p.tag == packetTagPublicSubkey {
+				tag = packetTagSecretSubkey
+			}
+			rawPacket{tag: tag, body: append(append([]byte{}, p.body...), secret...)}.serialize(&out)
+			continue
+		}
+		p.serialize(&out)
+	}
+	if len(secrets) > 0 {
+		return nil, errors.New("paperkey contains secret keys that are not part of the public key")
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"bytes"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"strings"
+	"testing"
+)
+
+func TestCrc24(t *testing.T) {
+	// Check values of CRC-24/OPENPGP.
+	for data, expected := range map[string]uint32{
+		"":          0xb704ce,
+		"123456789": 0x21cf02,
+	} {
+		if crc := crc24(crc24Init, []byte(data)); crc != expected {
+			t.Errorf("crc24(%q) = %06X, expected %06X", data, crc, expected)
+		}
+	}
+
+	// The checksum can be updated incrementally.
+	if crc := crc24(crc24(crc24Init, []byte("1234")), []byte("56789")); crc != 0x21cf02 {
+		t.Errorf("incremental crc24 = %06X, expected 21CF02", crc)
+	}
+}
+
+func TestPaperkey_roundTrip(t *testing.T) {
+	for name, algorithm := range map[string]packet.PublicKeyAlgorithm{
+		"rsa":   packet.PubKeyAlgoRSA,
+		"eddsa": packet.PubKeyAlgoEdDSA,
+	} {
+		t.Run(name, func(t *testing.T) {
+			secretKey, publicKey := testPaperkeyEntity(t, &packet.Config{Algorithm: algorithm, RSABits: 2048})
+
+			text, err := toPaperkey(secretKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(text, "# Secret portions of key ") {
+				t.Errorf("unexpected paperkey header %q", strings.SplitN(text, "\n", 2)[0])
+			}
+
+			restored, err := fromPaperkey(text, publicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(restored, secretKey) {
+				t.Errorf("restored secret key differs from the original")
+			}
+		})
+	}
+}
+
+func TestPaperkey_corrupted(t *testing.T) {
+	secretKey, publicKey := testPaperkeyEntity(t, &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	text, err := toPaperkey(secretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	last := len(lines) - 1
+
+	tests := map[string]struct {
+		text     string
+		expected string
+	}{
+		"data": {
+			// Flip the first data byte of line 1 without updating its checksum.
+			text:     strings.Replace(text, "  1: 00 ", "  1: 01 ", 1),
+			expected: "line 1 has a checksum mismatch",
+		},
+		"line checksum": {
+			text:     strings.Join(append(append([]string{}, lines[:last-1]...), lines[last-1][:len(lines[last-1])-6]+"000000", lines[last]), "\n"),
+			expected: "has a checksum mismatch",
+		},
+		"total checksum": {
+			text:     strings.Join(append(append([]string{}, lines[:last]...), lines[last][:len(lines[last])-6]+"000000"), "\n"),
+			expected: "checksum mismatch of the entire data",
+		},
+		"missing checksum": {
+			text:     strings.Join(lines[:last], "\n"),
+			expected: "missing checksum line",
+		},
+		"invalid checksum": {
+			text:     strings.Join(append(append([]string{}, lines[:last]...), lines[last][:len(lines[last])-6]+"XYZ"), "\n"),
+			expected: "has an invalid checksum",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := fromPaperkey(test.text, publicKey)
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("expected error containing %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestPaperkey_wrongPublicKey(t *testing.T) {
+	secretKey, _ := testPaperkeyEntity(t, &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	_, otherPublicKey := testPaperkeyEntity(t, &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+
+	text, err := toPaperkey(secretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fromPaperkey(text, otherPublicKey); err == nil || !strings.Contains(err.Error(), "does not contain the secret key") {
+		t.Errorf("expected an error for a different public key, got %v", err)
+	}
+}
+
+func TestPaperkey_version6(t *testing.T) {
+	secretKey, _ := testPaperkeyEntity(t, &packet.Config{Algorithm: packet.PubKeyAlgoEd25519, V6Keys: true})
+
+	if _, err := toPaperkey(secretKey); err != errPaperkeyVersion {
+		t.Errorf("expected %v, got %v", errPaperkeyVersion, err)
+	}
+}
+
+// testPaperkeyEntity generates a key and returns its binary secret and public key.
+func testPaperkeyEntity(t *testing.T, config *packet.Config) ([]byte, []byte) {
+	entity, err := openpgp.NewEntity("John Doe", "", "john.doe@example.com", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var secretKey, publicKey bytes.Buffer
+	if err := entity.SerializePrivate(&secretKey, config); err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(&publicKey); err != nil {
+		t.Fatal(err)
+	}
+	return secretKey.Bytes(), publicKey.Bytes()
+}
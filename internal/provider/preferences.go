@@ -0,0 +1,191 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"strings"
+)
+
+const (
+	featureMDC     = "mdc"
+	featureSEIPDv2 = "seipdv2"
+)
+
+// preferredCiphers maps the symmetric cipher names of the RFC 9580 registry to their IDs.
+var preferredCiphers = map[string]uint8{
+	"idea":        1,
+	"3des":        2,
+	"cast5":       3,
+	"blowfish":    4,
+	"aes128":      7,
+	"aes192":      8,
+	"aes256":      9,
+	"twofish":     10,
+	"camellia128": 11,
+	"camellia192": 12,
+	"camellia256": 13,
+}
+
+// preferredHashes maps the hash algorithm names of the RFC 9580 registry to their IDs. MD5 is omitted, as it must not
+// be advertised.
+var preferredHashes = map[string]uint8{
+	"sha1":      2,
+	"ripemd160": 3,
+	"sha256":    8,
+	"sha384":    9,
+	"sha512":    10,
+	"sha224":    11,
+	"sha3-256":  12,
+	"sha3-512":  14,
+}
+
+// preferredCompressions maps the compression algorithm names of the RFC 9580 registry to their IDs.
+var preferredCompressions = map[string]uint8{
+	"none":  0,
+	"zip":   1,
+	"zlib":  2,
+	"bzip2": 3,
+}
+
+// aeadCiphers are the ciphers of preferredCiphers with a block size of 128 bits, which can be combined with an AEAD
+// mode.
+var aeadCiphers = []string{"aes128", "aes192", "aes256", "camellia128", "camellia192", "camellia256", "twofish"}
+
+// preferencesModelV1 describes the algorithm preferences advertised in the self-signatures of a key. Unset lists keep
+// the preferences of the profile.
+type preferencesModelV1 struct {
+	Ciphers          types.List `tfsdk:"ciphers"`
+	Hashes           types.List `tfsdk:"hashes"`
+	Compression      types.List `tfsdk:"compression"`
+	AEADCiphersuites types.List `tfsdk:"aead_ciphersuites"`
+	Features         types.List `tfsdk:"features"`
+}
+
+// preferencesAttrTypes are the attribute types of preferencesModelV1.
+var preferencesAttrTypes = map[string]attr.Type{
+	"ciphers":           types.ListType{ElemType: types.StringType},
+	"hashes":            types.ListType{ElemType: types.StringType},
+	"compression":       types.ListType{ElemType: types.StringType},
+	"aead_ciphersuites": types.ListType{ElemType: types.StringType},
+	"features":          types.ListType{ElemType: types.StringType},
+}
+
+// resolvedPreferences holds the IDs of the preferences, nil for preferences that are not set.
+type resolvedPreferences struct {
+	ciphers          []uint8
+	hashes           []uint8
+	compression      []uint8
+	aeadCiphersuites [][2]uint8
+	features         []string
+}
+
+// resolve converts the names of the preferences into their IDs and validates them. Unknown lists are ignored.
+func (m *preferencesModelV1) resolve(ctx context.Context) (*resolvedPreferences, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	resolved := &resolvedPreferences{}
+
+	names := func(list types.List) []string {
+		if list.IsNull() || list.IsUnknown() {
+			return nil
+		}
+		var values []string
+		diags.Append(list.ElementsAs(ctx, &values, false)...)
+		if values == nil {
+			values = []string{}
+		}
+		return values
+	}
+	ids := func(attribute string, list types.List, known map[string]uint8) []uint8 {
+		values := names(list)
+		if values == nil {
+			return nil
+		}
+		result := []uint8{}
+		for _, name := range values {
+			id, ok := known[name]
+			if !ok {
+				diags.AddError("Invalid preferences", fmt.Sprintf("unknown %s %q, expected one of %q", attribute, name, sortedKeys(known)))
+				continue
+			}
+			result = append(result, id)
+		}
+		return result
+	}
+
+	resolved.ciphers = ids("cipher", m.Ciphers, preferredCiphers)
+	resolved.hashes = ids("hash", m.Hashes, preferredHashes)
+	resolved.compression = ids("compression algorithm", m.Compression, preferredCompressions)
+
+	if suites := names(m.AEADCiphersuites); suites != nil {
+		resolved.aeadCiphersuites = [][2]uint8{}
+		for _, suite := range suites {
+			cipher, mode, _ := strings.Cut(suite, "/")
+			aeadMode, modeOk := aeadModes[mode]
+			if !modeOk || !isAEADCipher(cipher) {
+				diags.AddError("Invalid preferences", fmt.Sprintf("invalid AEAD ciphersuite %q, expected <cipher>/<mode> with a cipher of %q and a mode of %q", suite, aeadCiphers, sortedKeys(aeadModes)))
+				continue
+			}
+			resolved.aeadCiphersuites = append(resolved.aeadCiphersuites, [2]uint8{preferredCiphers[cipher], uint8(aeadMode)})
+		}
+	}
+
+	if features := names(m.Features); features != nil {
+		resolved.features = features
+		for _, feature := range features {
+			if feature != featureMDC && feature != featureSEIPDv2 {
+				diags.AddError("Invalid preferences", fmt.Sprintf("unknown feature %q, expected one of %q", feature, []string{featureMDC, featureSEIPDv2}))
+			}
+		}
+		if len(resolved.aeadCiphersuites) > 0 && !resolved.hasFeature(featureSEIPDv2) {
+			diags.AddError("Invalid preferences", fmt.Sprintf("aead_ciphersuites require the feature %q", featureSEIPDv2))
+		}
+	}
+	return resolved, diags
+}
+
+// isAEADCipher returns whether the cipher can be used in an AEAD ciphersuite.
+func isAEADCipher(cipher string) bool {
+	for _, c := range aeadCiphers {
+		if c == cipher {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFeature returns whether the feature is set.
+func (p *resolvedPreferences) hasFeature(feature string) bool {
+	for _, f := range p.features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// update replaces the preferences of a self-signature carrying the key properties. Without explicit features, SEIPDv2
+// is advertised if AEAD ciphersuites are preferred.
+func (p *resolvedPreferences) update(sig *packet.Signature) {
+	if p.ciphers != nil {
+		sig.PreferredSymmetric = p.ciphers
+	}
+	if p.hashes != nil {
+		sig.PreferredHash = p.hashes
+	}
+	if p.compression != nil {
+		sig.PreferredCompression = p.compression
+	}
+	if p.aeadCiphersuites != nil {
+		sig.PreferredCipherSuites = p.aeadCiphersuites
+	}
+	if p.features != nil {
+		sig.SEIPDv1 = p.hasFeature(featureMDC)
+		sig.SEIPDv2 = p.hasFeature(featureSEIPDv2)
+	} else {
+		sig.SEIPDv2 = len(sig.PreferredCipherSuites) > 0
+	}
+}
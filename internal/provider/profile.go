@@ -0,0 +1,257 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/go-crypto/openpgp/s2k"
+	"github.com/ProtonMail/gopenpgp/v3/profile"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"sort"
+	"time"
+)
+
+const (
+	defaultProfileName = "gnupg"
+	defaultExpiry      = "0"
+
+	s2kModeIterated = "iterated"
+	s2kModeArgon2   = "argon2"
+
+	// Defaults of the go-crypto S2K implementation, see s2k.Config.
+	defaultS2KCount          = 16777216
+	defaultArgon2Passes      = 3
+	defaultArgon2Parallelism = 4
+	defaultArgon2Memory      = 64 * 1024
+
+	aeadModeNone = "none"
+)
+
+// ciphers maps the supported symmetric cipher names to their go-crypto identifiers.
+var ciphers = map[string]packet.CipherFunction{
+	"aes128": packet.CipherAES128,
+	"aes192": packet.CipherAES192,
+	"aes256": packet.CipherAES256,
+}
+
+// aeadModes maps the supported AEAD mode names to their go-crypto identifiers. aeadModeNone disables AEAD.
+var aeadModes = map[string]packet.AEADMode{
+	"eax": packet.AEADModeEAX,
+	"ocb": packet.AEADModeOCB,
+	"gcm": packet.AEADModeGCM,
+}
+
+// profiles maps the supported profile names to their constructors.
+var profiles = map[string]func() *profile.Custom{
+	"gnupg":   GnuPG,
+	"rfc4880": profile.RFC4880,
+	"rfc9580": profile.RFC9580,
+	"proton":  profile.ProtonV1,
+}
+
+// profileNames returns the sorted names of all supported profiles.
+func profileNames() []string {
+	return sortedKeys(profiles)
+}
+
+// profileByName returns a new instance of the named profile.
+func profileByName(name string) (*profile.Custom, error) {
+	newProfile, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q, expected one of %q", name, profileNames())
+	}
+	return newProfile(), nil
+}
+
+// sortedKeys returns the sorted keys of m.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseExpiry parses a key expiry given as Go duration into seconds. "0" means that the key does not expire.
+func parseExpiry(expiry string) (int32, error) {
+	d, err := time.ParseDuration(expiry)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("expiry %q must not be negative", expiry)
+	}
+	if d.Seconds() > float64(1<<31-1) {
+		return 0, fmt.Errorf("expiry %q is too long", expiry)
+	}
+	return int32(d.Seconds()), nil
+}
+
+// resolveString returns value if it is set, otherwise the provider default if that is set, otherwise fallback.
+func resolveString(value types.String, providerDefault types.String, fallback string) types.String {
+	if !value.IsNull() && !value.IsUnknown() {
+		return value
+	}
+	if !providerDefault.IsNull() && !providerDefault.IsUnknown() {
+		return providerDefault
+	}
+	return types.StringValue(fallback)
+}
+
+// s2kModelV1 describes the string-to-key settings used to protect private keys with a passphrase.
+type s2kModelV1 struct {
+	Mode              types.String `tfsdk:"mode"`
+	Count             types.Int64  `tfsdk:"count"`
+	Argon2Passes      types.Int64  `tfsdk:"argon2_passes"`
+	Argon2Parallelism types.Int64  `tfsdk:"argon2_parallelism"`
+	Argon2Memory      types.Int64  `tfsdk:"argon2_memory"`
+}
+
+// validate checks the S2K settings for consistency.
+func (m *s2kModelV1) validate() error {
+	if m.Mode.IsUnknown() {
+		return nil
+	}
+	switch m.Mode.ValueString() {
+	case s2kModeIterated:
+		if !m.Argon2Passes.IsNull() || !m.Argon2Parallelism.IsNull() || !m.Argon2Memory.IsNull() {
+			return fmt.Errorf("argon2 parameters are only supported with mode %q", s2kModeArgon2)
+		}
+		if !m.Count.IsNull() && !m.Count.IsUnknown() && (m.Count.ValueInt64() < 65536 || m.Count.ValueInt64() > 65011712) {
+			return fmt.Errorf("count must be between 65536 and 65011712")
+		}
+	case s2kModeArgon2:
+		if !m.Count.IsNull() {
+			return fmt.Errorf("count is only supported with mode %q", s2kModeIterated)
+		}
+		if !m.Argon2Passes.IsNull() && !m.Argon2Passes.IsUnknown() && (m.Argon2Passes.ValueInt64() < 1 || m.Argon2Passes.ValueInt64() > 255) {
+			return fmt.Errorf("argon2_passes must be between 1 and 255")
+		}
+		if !m.Argon2Parallelism.IsNull() && !m.Argon2Parallelism.IsUnknown() && (m.Argon2Parallelism.ValueInt64() < 1 || m.Argon2Parallelism.ValueInt64() > 255) {
+			return fmt.Errorf("argon2_parallelism must be between 1 and 255")
+		}
+		if !m.Argon2Memory.IsNull() && !m.Argon2Memory.IsUnknown() && (m.Argon2Memory.ValueInt64() < 8 || m.Argon2Memory.ValueInt64() > 1<<31) {
+			return fmt.Errorf("argon2_memory must be between 8 and 2147483648 KiB")
+		}
+	default:
+		return fmt.Errorf("unknown mode %q, expected one of %q", m.Mode.ValueString(), []string{s2kModeIterated, s2kModeArgon2})
+	}
+	return nil
+}
+
+// resolveS2K returns the effective S2K settings. Unset values are taken from the provider default if it uses the same
+// mode, otherwise from the defaults of the given profile.
+func resolveS2K(value *s2kModelV1, providerDefault *s2kModelV1, p *profile.Custom) *s2kModelV1 {
+	resolved := &s2kModelV1{}
+	if value != nil {
+		*resolved = *value
+	}
+	if resolved.Mode.IsNull() || resolved.Mode.IsUnknown() {
+		switch {
+		case providerDefault != nil:
+			*resolved = *providerDefault
+		case p.S2kKeyEncryption.Mode() == s2k.Argon2S2K:
+			resolved.Mode = types.StringValue(s2kModeArgon2)
+		default:
+			resolved.Mode = types.StringValue(s2kModeIterated)
+		}
+	}
+
+	fill := func(v types.Int64, fallback types.Int64, builtin int64) types.Int64 {
+		if !v.IsNull() && !v.IsUnknown() {
+			return v
+		}
+		if !fallback.IsNull() && !fallback.IsUnknown() {
+			return fallback
+		}
+		return types.Int64Value(builtin)
+	}
+	fallback := s2kModelV1{}
+	if providerDefault != nil && providerDefault.Mode.Equal(resolved.Mode) {
+		fallback = *providerDefault
+	}
+
+	switch resolved.Mode.ValueString() {
+	case s2kModeArgon2:
+		resolved.Count = types.Int64Null()
+		resolved.Argon2Passes = fill(resolved.Argon2Passes, fallback.Argon2Passes, defaultArgon2Passes)
+		resolved.Argon2Parallelism = fill(resolved.Argon2Parallelism, fallback.Argon2Parallelism, defaultArgon2Parallelism)
+		resolved.Argon2Memory = fill(resolved.Argon2Memory, fallback.Argon2Memory, defaultArgon2Memory)
+	default:
+		resolved.Count = fill(resolved.Count, fallback.Count, defaultS2KCount)
+		resolved.Argon2Passes = types.Int64Null()
+		resolved.Argon2Parallelism = types.Int64Null()
+		resolved.Argon2Memory = types.Int64Null()
+	}
+	return resolved
+}
+
+// config converts resolved S2K settings into the go-crypto representation.
+func (m *s2kModelV1) config() *s2k.Config {
+	if m.Mode.ValueString() == s2kModeArgon2 {
+		return &s2k.Config{
+			S2KMode: s2k.Argon2S2K,
+			Argon2Config: &s2k.Argon2Config{
+				NumberOfPasses:      uint8(m.Argon2Passes.ValueInt64()),
+				DegreeOfParallelism: uint8(m.Argon2Parallelism.ValueInt64()),
+				Memory:              uint32(m.Argon2Memory.ValueInt64()),
+			},
+		}
+	}
+	return &s2k.Config{
+		S2KMode:  s2k.IteratedSaltedS2K,
+		S2KCount: int(m.Count.ValueInt64()),
+	}
+}
+
+// s2kAttrTypes are the attribute types of s2kModelV1.
+var s2kAttrTypes = map[string]attr.Type{
+	"mode":               types.StringType,
+	"count":              types.Int64Type,
+	"argon2_passes":      types.Int64Type,
+	"argon2_parallelism": types.Int64Type,
+	"argon2_memory":      types.Int64Type,
+}
+
+// symmetricModelV1 describes the settings used to encrypt a message with a passphrase.
+type symmetricModelV1 struct {
+	Cipher   types.String
+	AEADMode types.String
+	S2K      *s2kModelV1
+}
+
+// validate checks the symmetric encryption settings for consistency.
+func (m *symmetricModelV1) validate() error {
+	if !m.Cipher.IsNull() && !m.Cipher.IsUnknown() {
+		if _, ok := ciphers[m.Cipher.ValueString()]; !ok {
+			return fmt.Errorf("unknown cipher %q, expected one of %q", m.Cipher.ValueString(), sortedKeys(ciphers))
+		}
+	}
+	if !m.AEADMode.IsNull() && !m.AEADMode.IsUnknown() && m.AEADMode.ValueString() != aeadModeNone {
+		if _, ok := aeadModes[m.AEADMode.ValueString()]; !ok {
+			return fmt.Errorf("unknown AEAD mode %q, expected one of %q", m.AEADMode.ValueString(), append(sortedKeys(aeadModes), aeadModeNone))
+		}
+	}
+	if m.S2K != nil {
+		return m.S2K.validate()
+	}
+	return nil
+}
+
+// apply overrides the message encryption settings of the profile with the values that are set.
+func (m *symmetricModelV1) apply(p *profile.Custom) {
+	if !m.Cipher.IsNull() {
+		p.CipherEncryption = ciphers[m.Cipher.ValueString()]
+	}
+	if !m.AEADMode.IsNull() {
+		if mode, ok := aeadModes[m.AEADMode.ValueString()]; ok {
+			p.AeadEncryption = &packet.AEADConfig{DefaultMode: mode}
+		} else {
+			p.AeadEncryption = nil
+		}
+	}
+	if m.S2K != nil {
+		p.S2kEncryption = resolveS2K(m.S2K, nil, p).config()
+	}
+}
@@ -2,16 +2,21 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure GpgProvider satisfies various provider interfaces.
 var _ provider.Provider = &GpgProvider{}
 var _ provider.ProviderWithFunctions = &GpgProvider{}
+var _ provider.ProviderWithEphemeralResources = &GpgProvider{}
 
 // GpgProvider defines the provider implementation.
 type GpgProvider struct {
@@ -21,8 +26,15 @@ type GpgProvider struct {
 	version string
 }
 
-// GpgProviderModel describes the provider data model.
+// GpgProviderModel describes the provider data model. It is passed to resources, which use its values as defaults
+// for their own unset attributes.
 type GpgProviderModel struct {
+	DefaultProfile   types.String `tfsdk:"default_profile"`
+	DefaultExpiry    types.String `tfsdk:"default_expiry"`
+	DefaultKeyserver types.String `tfsdk:"default_keyserver"`
+	DefaultWKDBase   types.String `tfsdk:"default_wkd_base"`
+	DefaultS2K       *s2kModelV1  `tfsdk:"default_s2k"`
+	DefaultComment   types.String `tfsdk:"default_comment"`
 }
 
 func (p *GpgProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -32,7 +44,54 @@ func (p *GpgProvider) Metadata(ctx context.Context, req provider.MetadataRequest
 
 func (p *GpgProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		Attributes: map[string]schema.Attribute{},
+		Attributes: map[string]schema.Attribute{
+			"default_profile": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: fmt.Sprintf("Default algorithm profile for generated keys, one of %q. Defaults to `%s`.", profileNames(), defaultProfileName),
+			},
+			"default_expiry": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Default expiry of generated keys as duration, e.g. `17520h`. `0` means that keys do not expire. Defaults to `0`.",
+			},
+			"default_keyserver": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Default keyserver URL for publishing keys, e.g. `hkps://keyserver.ubuntu.com`.",
+			},
+			"default_wkd_base": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Default base URL of the Web Key Directory for the `wkd_entries` of generated keys, e.g. `https://openpgpkey.example.com/.well-known/openpgpkey/example.com`.",
+			},
+			"default_s2k": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Default string-to-key settings for protecting private keys with a passphrase. Defaults to the settings of the profile.",
+				Attributes: map[string]schema.Attribute{
+					"mode": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: fmt.Sprintf("S2K mode, either `%s` or `%s`.", s2kModeIterated, s2kModeArgon2),
+					},
+					"count": schema.Int64Attribute{
+						Optional:            true,
+						MarkdownDescription: fmt.Sprintf("Iteration count for the `%s` mode.", s2kModeIterated),
+					},
+					"argon2_passes": schema.Int64Attribute{
+						Optional:            true,
+						MarkdownDescription: fmt.Sprintf("Number of passes for the `%s` mode.", s2kModeArgon2),
+					},
+					"argon2_parallelism": schema.Int64Attribute{
+						Optional:            true,
+						MarkdownDescription: fmt.Sprintf("Degree of parallelism for the `%s` mode.", s2kModeArgon2),
+					},
+					"argon2_memory": schema.Int64Attribute{
+						Optional:            true,
+						MarkdownDescription: fmt.Sprintf("Memory in KiB for the `%s` mode.", s2kModeArgon2),
+					},
+				},
+			},
+			"default_comment": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Default comment header of armored keys.",
+			},
+		},
 	}
 }
 
@@ -44,21 +103,82 @@ func (p *GpgProvider) Configure(ctx context.Context, req provider.ConfigureReque
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	if !data.DefaultProfile.IsNull() && !data.DefaultProfile.IsUnknown() {
+		if _, err := profileByName(data.DefaultProfile.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("default_profile"), "Invalid profile", err.Error())
+		}
+	}
+	if !data.DefaultExpiry.IsNull() && !data.DefaultExpiry.IsUnknown() {
+		if _, err := parseExpiry(data.DefaultExpiry.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("default_expiry"), "Invalid expiry", err.Error())
+		}
+	}
+	if !data.DefaultKeyserver.IsNull() && !data.DefaultKeyserver.IsUnknown() {
+		if _, err := hkpBaseURL(data.DefaultKeyserver.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("default_keyserver"), "Invalid keyserver URL", err.Error())
+		}
+	}
+	if !data.DefaultWKDBase.IsNull() && !data.DefaultWKDBase.IsUnknown() {
+		if err := validateWKDBase(data.DefaultWKDBase.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("default_wkd_base"), "Invalid WKD base URL", err.Error())
+		}
+	}
+	if data.DefaultS2K != nil {
+		if err := data.DefaultS2K.validate(); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("default_s2k"), "Invalid S2K settings", err.Error())
+		}
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.ResourceData = &data
+	resp.EphemeralResourceData = &data
 }
 
 func (p *GpgProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewKeyPairResource,
 		NewKeyResource,
+		NewKeyCertificationResource,
+		NewEncryptedMessageResource,
+		NewKeyserverPublicationResource,
+		NewKeySharesResource,
+		NewHomeResource,
+		NewSubkeyResource,
+		NewRotatingKeyPairResource,
+		NewKeyTransitionResource,
+	}
+}
+
+func (p *GpgProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewKeyPairEphemeralResource,
+		NewDecryptedMessageEphemeralResource,
 	}
 }
 
 func (p *GpgProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewVerifiedChecksumsDataSource,
+	}
 }
 
 func (p *GpgProvider) Functions(ctx context.Context) []func() function.Function {
-	return []func() function.Function{}
+	return []func() function.Function{
+		NewEncryptSymmetricFunction,
+		NewDecryptSymmetricFunction,
+		NewToSSHPublicKeyFunction,
+		NewToPaperkeyFunction,
+		NewFromPaperkeyFunction,
+		NewCombineSharesFunction,
+		NewInspectKeyFunction,
+		NewToAgentKeysFunction,
+		NewExportSecretSubkeysFunction,
+		NewOpenpgpkeyRecordFunction,
+	}
 }
 
 func New(version string) func() provider.Provider {
@@ -0,0 +1,440 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"time"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &RotatingKeyPairResource{}
+var _ resource.ResourceWithConfigure = &RotatingKeyPairResource{}
+var _ resource.ResourceWithValidateConfig = &RotatingKeyPairResource{}
+var _ resource.ResourceWithModifyPlan = &RotatingKeyPairResource{}
+
+// defaultKeepPrevious is the number of previous keys kept if keep_previous is not set.
+const defaultKeepPrevious = 1
+
+func NewRotatingKeyPairResource() resource.Resource {
+	return &RotatingKeyPairResource{}
+}
+
+type RotatingKeyPairResource struct {
+	// defaults holds the provider configuration, it is nil if the provider has not been configured.
+	defaults *GpgProviderModel
+}
+
+func (g *RotatingKeyPairResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	defaults, ok := req.ProviderData.(*GpgProviderModel)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *GpgProviderModel, got %T.", req.ProviderData))
+		return
+	}
+	g.defaults = defaults
+}
+
+func (g RotatingKeyPairResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rotating_key_pair"
+}
+
+func (g RotatingKeyPairResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A resource for a key pair that is replaced by a new key pair when the rotation period has elapsed, " +
+			"while the previous key pairs are kept for decryption. The rotation is planned as update once the plan is " +
+			"created after `next_rotation`. The key pairs use the provider defaults for the profile, expiry, S2K settings " +
+			"and comment.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprint of the current key pair.",
+			},
+			"identities": schema.ListNestedAttribute{
+				Description: "List of identities for the GPG key pairs. Due to limitations in the underlying library only one identity is supported at the moment.",
+				Required:    true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "Name",
+							Required:    true,
+						},
+						"email": schema.StringAttribute{
+							Description: "Email",
+							Required:    true,
+						},
+					},
+				},
+			},
+			"passphrase": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Passphrase for locking the private keys.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"rotation_period": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Period after which the key pair is rotated as duration, e.g. `8760h`. Changing the period moves `next_rotation` without rotating the key pair, unless the new rotation time has passed.",
+			},
+			"keep_previous": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Number of previous key pairs to keep in `previous_keys`. Defaults to `1`.",
+			},
+			"fingerprint": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprint of the current key pair.",
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the current key pair in armored format.",
+			},
+			"private_key": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private key of the current key pair in armored format.",
+			},
+			"rotated_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Creation time of the current key pair in RFC 3339 format.",
+			},
+			"next_rotation": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Time after which the key pair is rotated in RFC 3339 format.",
+			},
+			"previous_keys": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Previous key pairs, newest first, for decrypting messages encrypted to them.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"fingerprint": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Fingerprint of the key pair.",
+						},
+						"public_key": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Public key in armored format.",
+						},
+						"private_key": schema.StringAttribute{
+							Computed:            true,
+							Sensitive:           true,
+							MarkdownDescription: "Private key in armored format.",
+						},
+					},
+				},
+			},
+			"transition_statement": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Statement linking the fingerprints of the previous and the current key pair, clearsigned by both keys. Null until the first rotation.",
+			},
+		},
+	}
+}
+
+func (g RotatingKeyPairResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data rotatingKeyPairModelV1
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.RotationPeriod.IsNull() && !data.RotationPeriod.IsUnknown() {
+		if _, err := parseRotationPeriod(data.RotationPeriod.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("rotation_period"), "Invalid rotation period", err.Error())
+		}
+	}
+	if !data.KeepPrevious.IsNull() && !data.KeepPrevious.IsUnknown() && data.KeepPrevious.ValueInt64() < 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("keep_previous"), "Invalid number of previous keys", "keep_previous must not be negative.")
+	}
+
+	if data.Identities != nil {
+		resp.Diagnostics.Append(validateKeyPairSettings(ctx, data.Identities, types.StringNull(), types.StringNull(), types.ObjectNull(s2kAttrTypes))...)
+	}
+}
+
+// ModifyPlan keeps the current key pair until the rotation period has elapsed, moves the next rotation when the period
+// changes and drops previous key pairs beyond keep_previous. Once the rotation is due, the keys are marked as unknown
+// and rotated by Update.
+func (g RotatingKeyPairResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+
+	var plan, state rotatingKeyPairModelV1
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() || plan.RotationPeriod.IsUnknown() || plan.KeepPrevious.IsUnknown() {
+		return
+	}
+
+	period, err := parseRotationPeriod(plan.RotationPeriod.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("rotation_period"), "Invalid rotation period", err.Error())
+		return
+	}
+	rotatedAt, err := time.Parse(time.RFC3339, state.RotatedAt.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("GPG key rotation failed", fmt.Sprintf("Parsing rotated_at failed with error: %s", err))
+		return
+	}
+	nextRotation := rotatedAt.Add(period)
+
+	plan.Id = state.Id
+	plan.Fingerprint = state.Fingerprint
+	plan.PublicKey = state.PublicKey
+	plan.PrivateKey = state.PrivateKey
+	plan.RotatedAt = state.RotatedAt
+	plan.NextRotation = types.StringValue(nextRotation.UTC().Format(time.RFC3339))
+	plan.TransitionStatement = state.TransitionStatement
+
+	if !time.Now().Before(nextRotation) {
+		plan.Id = types.StringUnknown()
+		plan.Fingerprint = types.StringUnknown()
+		plan.PublicKey = types.StringUnknown()
+		plan.PrivateKey = types.StringUnknown()
+		plan.RotatedAt = types.StringUnknown()
+		plan.NextRotation = types.StringUnknown()
+		plan.PreviousKeys = types.ListUnknown(types.ObjectType{AttrTypes: previousKeyAttrTypes})
+		plan.TransitionStatement = types.StringUnknown()
+	} else {
+		previousKeys, diags := trimPreviousKeys(ctx, state.PreviousKeys, nil, plan.keepPrevious())
+		resp.Diagnostics.Append(diags...)
+		plan.PreviousKeys = previousKeys
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+func (g RotatingKeyPairResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data rotatingKeyPairModelV1
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(g.rotate(ctx, &data, nil)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (g RotatingKeyPairResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Nothing to do here, the rotation is detected when planning.
+}
+
+// Update rotates the key pair if ModifyPlan marked the keys as unknown, otherwise the plan is copied to the state.
+func (g RotatingKeyPairResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var model, state rotatingKeyPairModelV1
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &model)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if model.Fingerprint.IsUnknown() {
+		resp.Diagnostics.Append(g.rotate(ctx, &model, &state)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
+
+func (g RotatingKeyPairResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Nothing to do here.
+}
+
+// rotate generates a new key pair for the model. If there is a previous state, its key pair is added to the previous
+// keys and the transition to the new key pair is signed by both keys.
+func (g RotatingKeyPairResource) rotate(ctx context.Context, data *rotatingKeyPairModelV1, previous *rotatingKeyPairModelV1) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	period, err := parseRotationPeriod(data.RotationPeriod.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("rotation_period"), "GPG key rotation failed", err.Error())
+		return diags
+	}
+
+	settings := keyPairGeneration{
+		Identities: data.Identities,
+		Passphrase: data.Passphrase.ValueString(),
+		Profile:    types.StringNull(),
+		Expiry:     types.StringNull(),
+		S2K:        types.ObjectNull(s2kAttrTypes),
+		Comment:    types.StringNull(),
+	}
+	keys, keyDiags := generateKeyPair(ctx, g.defaults, &settings)
+	diags.Append(keyDiags...)
+
+	if diags.HasError() {
+		return diags
+	}
+
+	rotatedAt, err := time.Parse(time.RFC3339, keys.CreatedAt.ValueString())
+	if err != nil {
+		diags.AddError("GPG key rotation failed", fmt.Sprintf("Parsing the creation time failed with error: %s", err))
+		return diags
+	}
+
+	data.Id = keys.Fingerprint
+	data.Fingerprint = keys.Fingerprint
+	data.PublicKey = keys.PublicKey
+	data.PrivateKey = keys.PrivateKey
+	data.RotatedAt = keys.CreatedAt
+	data.NextRotation = types.StringValue(rotatedAt.Add(period).UTC().Format(time.RFC3339))
+	data.TransitionStatement = types.StringNull()
+
+	if previous == nil {
+		data.PreviousKeys, keyDiags = trimPreviousKeys(ctx, types.ListNull(types.ObjectType{AttrTypes: previousKeyAttrTypes}), nil, 0)
+		diags.Append(keyDiags...)
+		return diags
+	}
+
+	data.PreviousKeys, keyDiags = trimPreviousKeys(ctx, previous.PreviousKeys, &previousKeyModelV1{
+		Fingerprint: previous.Fingerprint,
+		PublicKey:   previous.PublicKey,
+		PrivateKey:  previous.PrivateKey,
+	}, data.keepPrevious())
+	diags.Append(keyDiags...)
+
+	oldKey, err := unlockArmoredKey(previous.PrivateKey.ValueString(), previous.Passphrase.ValueString())
+	if err != nil {
+		diags.AddError("GPG key rotation failed", fmt.Sprintf("Unlocking the previous key failed with error: %s", err))
+		return diags
+	}
+	defer oldKey.ClearPrivateParams()
+	newKey, err := unlockArmoredKey(keys.PrivateKey.ValueString(), data.Passphrase.ValueString())
+	if err != nil {
+		diags.AddError("GPG key rotation failed", fmt.Sprintf("Unlocking the new key failed with error: %s", err))
+		return diags
+	}
+	defer newKey.ClearPrivateParams()
+
+	statement := keyTransitionStatement(previous.Fingerprint.ValueString(), keys.Fingerprint.ValueString(), rotatedAt,
+		fmt.Sprintf("Scheduled rotation after %s.", data.RotationPeriod.ValueString()))
+	transition, err := clearsignWithPrimaryKeys(statement, oldKey.GetEntity().PrivateKey, newKey.GetEntity().PrivateKey)
+	if err != nil {
+		diags.AddError("GPG key rotation failed", fmt.Sprintf("Signing the transition statement failed with error: %s", err))
+		return diags
+	}
+	data.TransitionStatement = types.StringValue(transition)
+
+	return diags
+}
+
+type rotatingKeyPairModelV1 struct {
+	Id                  types.String      `tfsdk:"id"`
+	Identities          []identityModelV1 `tfsdk:"identities"`
+	Passphrase          types.String      `tfsdk:"passphrase"`
+	RotationPeriod      types.String      `tfsdk:"rotation_period"`
+	KeepPrevious        types.Int64       `tfsdk:"keep_previous"`
+	Fingerprint         types.String      `tfsdk:"fingerprint"`
+	PublicKey           types.String      `tfsdk:"public_key"`
+	PrivateKey          types.String      `tfsdk:"private_key"`
+	RotatedAt           types.String      `tfsdk:"rotated_at"`
+	NextRotation        types.String      `tfsdk:"next_rotation"`
+	PreviousKeys        types.List        `tfsdk:"previous_keys"`
+	TransitionStatement types.String      `tfsdk:"transition_statement"`
+}
+
+// keepPrevious returns the number of previous key pairs to keep.
+func (m rotatingKeyPairModelV1) keepPrevious() int {
+	if m.KeepPrevious.IsNull() {
+		return defaultKeepPrevious
+	}
+	return int(m.KeepPrevious.ValueInt64())
+}
+
+type previousKeyModelV1 struct {
+	Fingerprint types.String `tfsdk:"fingerprint"`
+	PublicKey   types.String `tfsdk:"public_key"`
+	PrivateKey  types.String `tfsdk:"private_key"`
+}
+
+// previousKeyAttrTypes are the attribute types of previousKeyModelV1.
+var previousKeyAttrTypes = map[string]attr.Type{
+	"fingerprint": types.StringType,
+	"public_key":  types.StringType,
+	"private_key": types.StringType,
+}
+
+// trimPreviousKeys returns the previous key pairs with the newest key pair prepended, if set, limited to keep entries.
+func trimPreviousKeys(ctx context.Context, previousKeys types.List, newest *previousKeyModelV1, keep int) (types.List, diag.Diagnostics) {
+	keys := []previousKeyModelV1{}
+	var diags diag.Diagnostics
+
+	if newest != nil {
+		keys = append(keys, *newest)
+	}
+	if !previousKeys.IsNull() && !previousKeys.IsUnknown() {
+		var existing []previousKeyModelV1
+		diags.Append(previousKeys.ElementsAs(ctx, &existing, false)...)
+		keys = append(keys, existing...)
+	}
+	if len(keys) > keep {
+		keys = keys[:keep]
+	}
+
+	list, listDiags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: previousKeyAttrTypes}, keys)
+	diags.Append(listDiags...)
+	return list, diags
+}
+
+// parseRotationPeriod parses a rotation period given as Go duration, which must be positive.
+func parseRotationPeriod(period string) (time.Duration, error) {
+	d, err := time.ParseDuration(period)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("rotation period %q must be positive", period)
+	}
+	return d, nil
+}
+
+// unlockArmoredKey returns an unlocked copy of an armored private key.
+func unlockArmoredKey(armoredKey string, passphrase string) (*gpgcrypto.Key, error) {
+	key, err := gpgcrypto.NewKeyFromArmored(armoredKey)
+	if err != nil {
+		return nil, err
+	}
+	locked, err := key.IsLocked()
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return key, nil
+	}
+	return key.Unlock([]byte(passphrase))
+}
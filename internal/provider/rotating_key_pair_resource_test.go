@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestAccRotatingKeyPairResource(t *testing.T) {
+	var firstFingerprint string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccRotatingKeyPairResourceConfig("3s", ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("gpg_rotating_key_pair.test", "id", "gpg_rotating_key_pair.test", "fingerprint"),
+					resource.TestCheckResourceAttr("gpg_rotating_key_pair.test", "previous_keys.#", "0"),
+					resource.TestCheckNoResourceAttr("gpg_rotating_key_pair.test", "transition_statement"),
+					testAccCheckRotationTimes("gpg_rotating_key_pair.test", 3*time.Second),
+					func(s *terraform.State) error {
+						firstFingerprint = s.RootModule().Resources["gpg_rotating_key_pair.test"].Primary.Attributes["fingerprint"]
+						return nil
+					},
+				),
+			},
+			// Rotation testing
+			{
+				PreConfig: func() { time.Sleep(4 * time.Second) },
+				Config:    testAccRotatingKeyPairResourceConfig("3s", ""),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_rotating_key_pair.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_rotating_key_pair.test", "previous_keys.#", "1"),
+					resource.TestCheckResourceAttrWith("gpg_rotating_key_pair.test", "previous_keys.0.fingerprint", func(value string) error {
+						if value != firstFingerprint {
+							return fmt.Errorf("expected previous key %s, got %s", firstFingerprint, value)
+						}
+						return nil
+					}),
+					resource.TestCheckResourceAttrWith("gpg_rotating_key_pair.test", "fingerprint", func(value string) error {
+						if value == firstFingerprint {
+							return fmt.Errorf("expected the key pair to be rotated")
+						}
+						return nil
+					}),
+					testAccCheckRotationTimes("gpg_rotating_key_pair.test", 3*time.Second),
+					testAccCheckKeyTransitionStatement("gpg_rotating_key_pair.test", filepath.Join(t.TempDir(), "gnupg")),
+				),
+			},
+			// Update testing
+			{
+				Config: testAccRotatingKeyPairResourceConfig("1h", "keep_previous = 0"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_rotating_key_pair.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectKnownValue("gpg_rotating_key_pair.test", tfjsonpath.New("fingerprint"), knownvalue.NotNull()),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("gpg_rotating_key_pair.test", "previous_keys.#", "0"),
+					testAccCheckRotationTimes("gpg_rotating_key_pair.test", time.Hour),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+// testAccCheckRotationTimes checks that the next rotation is the period after the last rotation.
+func testAccCheckRotationTimes(name string, period time.Duration) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+		rotatedAt, err := time.Parse(time.RFC3339, rs.Primary.Attributes["rotated_at"])
+		if err != nil {
+			return err
+		}
+		nextRotation, err := time.Parse(time.RFC3339, rs.Primary.Attributes["next_rotation"])
+		if err != nil {
+			return err
+		}
+		if !nextRotation.Equal(rotatedAt.Add(period)) {
+			return fmt.Errorf("expected next rotation %s after %s, got %s", period, rotatedAt, nextRotation)
+		}
+		return nil
+	}
+}
+
+// testAccCheckKeyTransitionStatement checks the transition statement from the previous to the current key pair.
+func testAccCheckKeyTransitionStatement(name string, home string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+		return testAccVerifyKeyTransitionStatement(rs.Primary.Attributes["transition_statement"], rs.Primary.Attributes["previous_keys.0.public_key"], rs.Primary.Attributes["public_key"], home)
+	}
+}
+
+func testAccRotatingKeyPairResourceConfig(period string, extra string) string {
+	return fmt.Sprintf(`
+resource "gpg_rotating_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase      = "top secret"
+  rotation_period = %q
+  %s
+}
+`, period, extra)
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"github.com/ProtonMail/gopenpgp/v3/armor"
+	"github.com/ProtonMail/gopenpgp/v3/constants"
+	"slices"
+)
+
+// gnuDummyS2K is the S2K specifier of the GNU extension for secret keys without secret key material: the GNU S2K
+// type 101, hash algorithm 0, "GNU" and the protection mode 1 for "no secret key".
+var gnuDummyS2K = []byte{101, 0, 'G', 'N', 'U', 1}
+
+// exportSecretSubkeys serializes a private key with the secret key material of the primary key replaced by a GNU
+// dummy, like `gpg --export-secret-subkeys`. The subkeys keep their secret key material and protection, and the
+// self-signatures are not re-signed, as the primary key is not needed.
+func exportSecretSubkeys(entity *openpgp.Entity) ([]byte, error) {
+	if entity.PrivateKey == nil {
+		return nil, errors.New("the key is not a private key")
+	}
+	if !slices.ContainsFunc(entity.Subkeys, func(subkey openpgp.Subkey) bool {
+		return subkey.PrivateKey != nil && !subkey.PrivateKey.Dummy()
+	}) {
+		return nil, errors.New("the key has no subkeys with secret key material")
+	}
+
+	primary, err := gnuDummyPrivateKey(entity.PrimaryKey)
+	if err != nil {
+		return nil, err
+	}
+
+	stripped := *entity
+	stripped.PrivateKey = primary
+
+	var buf bytes.Buffer
+	if err := stripped.SerializePrivateWithoutSigning(&buf, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gnuDummyPrivateKey returns a secret key packet of the public key without secret key material. Version 4 keys use
+// the simple checksum usage octet written by GnuPG, while version 6 keys require the SHA-1 usage octet and the
+// lengths of the optional fields.
+func gnuDummyPrivateKey(pub *packet.PublicKey) (*packet.PrivateKey, error) {
+	var public bytes.Buffer
+	if err := pub.Serialize(&public); err != nil {
+		return nil, err
+	}
+	bodies, err := packetBodies(public.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if len(bodies) != 1 {
+		return nil, fmt.Errorf("expected one public key packet, got %d", len(bodies))
+	}
+
+	body := bytes.Clone(public.Bytes()[bodies[0].offset : bodies[0].offset+bodies[0].length])
+	switch pub.Version {
+	case 4:
+		body = append(body, byte(packet.S2KCHECKSUM), 0)
+	case 6:
+		body = append(body, byte(packet.S2KSHA1), byte(2+len(gnuDummyS2K)), 0, byte(len(gnuDummyS2K)))
+	default:
+		return nil, fmt.Errorf("unsupported key version %d", pub.Version)
+	}
+	body = append(body, gnuDummyS2K...)
+
+	// New format header of a secret key packet with a four-octet length.
+	header := []byte{0xc0 | 5, 0xff, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(header[2:], uint32(len(body)))
+
+	p, err := packet.Read(bytes.NewReader(append(header, body...)))
+	if err != nil {
+		return nil, err
+	}
+	priv, ok := p.(*packet.PrivateKey)
+	if !ok || !priv.Dummy() {
+		return nil, errors.New("invalid GNU dummy secret key packet")
+	}
+	return priv, nil
+}
+
+// armorPrivateKey armors a serialized private key like the keys of the key pair resource, with a checksum for keys
+// other than version 6.
+func armorPrivateKey(entity *openpgp.Entity, serialized []byte, comment string) (string, error) {
+	return armor.ArmorWithTypeAndCustomHeadersChecksum(serialized, constants.PrivateKeyHeader, "", comment, entity.PrimaryKey.Version != 6)
+}
@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"strings"
+)
+
+// notationModelV1 describes a notation data subpacket of a self-signature.
+type notationModelV1 struct {
+	Name          types.String `tfsdk:"name"`
+	Value         types.String `tfsdk:"value"`
+	HumanReadable types.Bool   `tfsdk:"human_readable"`
+	Critical      types.Bool   `tfsdk:"critical"`
+	Email         types.String `tfsdk:"email"`
+}
+
+// validate checks the name and value of the notation and that it belongs to one of the identities. Unknown values are
+// skipped.
+func (m notationModelV1) validate(identities []identityModelV1) error {
+	if !m.Name.IsUnknown() {
+		name := m.Name.ValueString()
+		local, domain, ok := strings.Cut(name, "@")
+		if !ok || local == "" || domain == "" {
+			return fmt.Errorf("notation name %q must be in the format name@domain", name)
+		}
+		if name == packet.SaltNotationName {
+			return fmt.Errorf("notation name %q is reserved", name)
+		}
+	}
+	if !m.Value.IsUnknown() && !m.humanReadable() {
+		if _, err := hex.DecodeString(m.Value.ValueString()); err != nil {
+			return fmt.Errorf("value of notation %q must be in hex format unless human_readable is set: %w", m.Name.ValueString(), err)
+		}
+	}
+	if !m.Email.IsNull() && !m.Email.IsUnknown() {
+		for _, identity := range identities {
+			if identity.Email.IsUnknown() || identity.Email.ValueString() == m.Email.ValueString() {
+				return nil
+			}
+		}
+		return fmt.Errorf("notation %q refers to the unknown identity %q", m.Name.ValueString(), m.Email.ValueString())
+	}
+	return nil
+}
+
+// humanReadable returns whether the value of the notation is text, which is the default.
+func (m notationModelV1) humanReadable() bool {
+	return m.HumanReadable.IsNull() || m.HumanReadable.ValueBool()
+}
+
+// notation converts the model into a notation subpacket.
+func (m notationModelV1) notation() (*packet.Notation, error) {
+	value := []byte(m.Value.ValueString())
+	if !m.humanReadable() {
+		var err error
+		if value, err = hex.DecodeString(m.Value.ValueString()); err != nil {
+			return nil, err
+		}
+	}
+	return &packet.Notation{
+		Name:            m.Name.ValueString(),
+		Value:           value,
+		IsCritical:      m.Critical.ValueBool(),
+		IsHumanReadable: m.humanReadable(),
+	}, nil
+}
+
+// selfSignatureSettings holds the subpackets that are added to the self-signatures of a generated key.
+type selfSignatureSettings struct {
+	preferences        *resolvedPreferences
+	notations          []notationModelV1
+	preferredKeyserver string
+	policyURI          string
+}
+
+// empty returns whether the self-signatures are kept as generated.
+func (s *selfSignatureSettings) empty() bool {
+	return s.preferences == nil && len(s.notations) == 0 && s.preferredKeyserver == "" && s.policyURI == ""
+}
+
+// apply updates the self-signatures of the unlocked entity and signs them again. Preferences, the preferred keyserver,
+// the policy URI and notations without email are added to the self-signatures carrying the key properties, i.e. the
+// user ID self-signatures of v4 keys and the direct key signature of v6 keys. Notations with email are added to the
+// self-signature of the user ID with that email.
+func (s *selfSignatureSettings) apply(entity *openpgp.Entity, config *packet.Config) error {
+	update := func(sig *packet.Signature, email string) error {
+		if sig.PreferredSymmetric != nil {
+			if s.preferences != nil {
+				s.preferences.update(sig)
+			}
+			if s.preferredKeyserver != "" {
+				sig.PreferredKeyserver = s.preferredKeyserver
+			}
+			if s.policyURI != "" {
+				sig.PolicyURI = s.policyURI
+			}
+		}
+		for _, m := range s.notations {
+			if m.Email.IsNull() && sig.PreferredSymmetric == nil || !m.Email.IsNull() && m.Email.ValueString() != email {
+				continue
+			}
+			notation, err := m.notation()
+			if err != nil {
+				return err
+			}
+			sig.Notations = append(sig.Notations, notation)
+		}
+		return nil
+	}
+
+	for _, direct := range entity.DirectSignatures {
+		sig := direct.Packet
+		if err := update(sig, ""); err != nil {
+			return err
+		}
+		if err := renewSalt(sig, config); err != nil {
+			return err
+		}
+		if err := sig.SignDirectKeyBinding(entity.PrimaryKey, entity.PrivateKey, config); err != nil {
+			return err
+		}
+	}
+	for _, identity := range entity.Identities {
+		for _, selfCertification := range identity.SelfCertifications {
+			sig := selfCertification.Packet
+			if err := update(sig, identity.UserId.Email); err != nil {
+				return err
+			}
+			if err := renewSalt(sig, config); err != nil {
+				return err
+			}
+			if err := sig.SignUserId(identity.UserId.Id, entity.PrimaryKey, entity.PrivateKey, config); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// renewSalt replaces the salt of a v6 signature before it is signed again, as go-crypto only generates a salt if the
+// signature has none and a salt must not be reused for different signatures. Signing v4 signatures renews their salt
+// notation.
+func renewSalt(sig *packet.Signature, config *packet.Config) error {
+	if sig.Version != 6 {
+		return nil
+	}
+	salt, err := packet.SignatureSaltForHash(sig.Hash, config.Random())
+	if err != nil {
+		return err
+	}
+	return sig.SetSalt(salt)
+}
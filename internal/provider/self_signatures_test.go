@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"bytes"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"testing"
+)
+
+func TestSelfSignatureSettingsApplyRenewsSalt(t *testing.T) {
+	config := &packet.Config{Algorithm: packet.PubKeyAlgoEd25519, V6Keys: true}
+	entity, err := openpgp.NewEntity("John Doe", "", "john.doe@example.com", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var signatures []*packet.Signature
+	for _, direct := range entity.DirectSignatures {
+		signatures = append(signatures, direct.Packet)
+	}
+	for _, identity := range entity.Identities {
+		for _, selfCertification := range identity.SelfCertifications {
+			signatures = append(signatures, selfCertification.Packet)
+		}
+	}
+	if len(signatures) < 2 {
+		t.Fatalf("expected a direct key signature and a user ID self-signature, got %d signatures", len(signatures))
+	}
+	salts := make([][]byte, len(signatures))
+	for i, sig := range signatures {
+		if sig.Version != 6 || len(sig.Salt()) == 0 {
+			t.Fatalf("expected a salted v6 signature, got version %d", sig.Version)
+		}
+		salts[i] = bytes.Clone(sig.Salt())
+	}
+
+	settings := selfSignatureSettings{policyURI: "https://example.com/policy"}
+	if err := settings.apply(entity, config); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, sig := range signatures {
+		if bytes.Equal(sig.Salt(), salts[i]) {
+			t.Errorf("expected signature %d to be signed again with a new salt", i)
+		}
+	}
+	if _, err := entity.VerifyPrimaryKey(config.Now(), nil); err != nil {
+		t.Errorf("expected the direct key signature to verify: %s", err)
+	}
+	for id, identity := range entity.Identities {
+		if _, err := identity.Verify(config.Now(), nil); err != nil {
+			t.Errorf("expected the self-signature of %q to verify: %s", id, err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// shareFormatPrefix identifies key shares and the version of their format.
+const shareFormatPrefix = "gpg-share-v1"
+
+// gfMul multiplies two elements of GF(2^8) modulo the AES polynomial x^8 + x^4 + x^3 + x + 1.
+func gfMul(a, b byte) byte {
+	var product byte
+	for i := 0; i < 8; i++ {
+		if b&1 != 0 {
+			product ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return product
+}
+
+// gfInv returns the multiplicative inverse of a non-zero element of GF(2^8), which is a^254.
+func gfInv(a byte) byte {
+	result := byte(1)
+	for i := 0; i < 254; i++ {
+		result = gfMul(result, a)
+	}
+	return result
+}
+
+// keyShare is one share of a secret split with Shamir's secret sharing scheme.
+type keyShare struct {
+	// Fingerprint of the key the share belongs to.
+	Fingerprint string
+	// Threshold is the number of shares needed to recover the secret.
+	Threshold int
+	// X is the evaluation point of the share, between 1 and 255.
+	X byte
+	// Y holds the evaluations of the polynomials of all secret bytes at X.
+	Y []byte
+}
+
+// String encodes the share as a single line.
+func (s keyShare) String() string {
+	return fmt.Sprintf("%s:%s:%d:%d:%s", shareFormatPrefix, s.Fingerprint, s.Threshold, s.X, base64.StdEncoding.EncodeToString(s.Y))
+}
+
+// parseKeyShare decodes a share encoded with keyShare.String.
+func parseKeyShare(share string) (keyShare, error) {
+	fields := strings.Split(strings.TrimSpace(share), ":")
+	if len(fields) != 5 || fields[0] != shareFormatPrefix {
+		return keyShare{}, errors.New("not a key share")
+	}
+	threshold, err := strconv.ParseUint(fields[2], 10, 8)
+	if err != nil {
+		return keyShare{}, fmt.Errorf("invalid threshold: %w", err)
+	}
+	x, err := strconv.ParseUint(fields[3], 10, 8)
+	if err != nil || x == 0 {
+		return keyShare{}, errors.New("invalid share index")
+	}
+	y, err := base64.StdEncoding.DecodeString(fields[4])
+	if err != nil {
+		return keyShare{}, fmt.Errorf("invalid share data: %w", err)
+	}
+	return keyShare{Fingerprint: fields[1], Threshold: int(threshold), X: byte(x), Y: y}, nil
+}
+
+// splitSecret splits the secret into n shares of which threshold are needed to recover it. Every byte of the secret is
+// the constant term of a random polynomial of degree threshold-1, share i holds the evaluations at x = i.
+func splitSecret(secret []byte, fingerprint string, n int, threshold int) ([]keyShare, error) {
+	if threshold < 2 || threshold > n || n > 255 {
+		return nil, fmt.Errorf("invalid number of shares %d with threshold %d", n, threshold)
+	}
+
+	shares := make([]keyShare, n)
+	for i := range shares {
+		shares[i] = keyShare{Fingerprint: fingerprint, Threshold: threshold, X: byte(i + 1), Y: make([]byte, len(secret))}
+	}
+
+	coefficients := make([]byte, threshold)
+	defer clear(coefficients)
+	for j, b := range secret {
+		coefficients[0] = b
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, err
+		}
+		for i := range shares {
+			// Horner's method
+			var y byte
+			for k := threshold - 1; k >= 0; k-- {
+				y = gfMul(y, shares[i].X) ^ coefficients[k]
+			}
+			shares[i].Y[j] = y
+		}
+	}
+	return shares, nil
+}
+
+// combineShares recovers the secret from at least threshold shares with Lagrange interpolation at x = 0.
+func combineShares(shares []keyShare) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+	first := shares[0]
+	if len(shares) < first.Threshold {
+		return nil, fmt.Errorf("%d shares given, but %d are needed", len(shares), first.Threshold)
+	}
+	seen := make(map[byte]bool)
+	for _, share := range shares {
+		if share.Fingerprint != first.Fingerprint || share.Threshold != first.Threshold || len(share.Y) != len(first.Y) {
+			return nil, errors.New("shares belong to different keys")
+		}
+		if seen[share.X] {
+			return nil, fmt.Errorf("share %d is given twice", share.X)
+		}
+		seen[share.X] = true
+	}
+
+	secret := make([]byte, len(first.Y))
+	for i, share := range shares {
+		// Lagrange basis polynomial of share i at x = 0; subtraction is XOR in GF(2^8).
+		basis := byte(1)
+		for j, other := range shares {
+			if i == j {
+				continue
+			}
+			basis = gfMul(basis, gfMul(other.X, gfInv(other.X^share.X)))
+		}
+		for k, y := range share.Y {
+			secret[k] ^= gfMul(basis, y)
+		}
+	}
+	return secret, nil
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/rand"
+	"strings"
+	"testing"
+)
+
+func TestGfInv(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		if product := gfMul(byte(a), gfInv(byte(a))); product != 1 {
+			t.Errorf("%#02x * gfInv(%#02x) = %#02x, expected 1", a, a, product)
+		}
+	}
+}
+
+func TestShamir_roundTrip(t *testing.T) {
+	secret := make([]byte, 64)
+	if _, err := rand.Read(secret); err != nil {
+		t.Fatal(err)
+	}
+
+	shares, err := splitSecret(secret, "ABCD", 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("expected 5 shares, got %d", len(shares))
+	}
+
+	// Any combination of at least threshold shares recovers the secret.
+	for _, indices := range [][]int{{0, 1, 2}, {0, 2, 4}, {4, 3, 1}, {1, 2, 3, 4}, {0, 1, 2, 3, 4}} {
+		var subset []keyShare
+		for _, i := range indices {
+			// Shares are passed around in their string encoding.
+			parsed, err := parseKeyShare(shares[i].String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			subset = append(subset, parsed)
+		}
+		recovered, err := combineShares(subset)
+		if err != nil {
+			t.Fatalf("shares %v: %s", indices, err)
+		}
+		if !bytes.Equal(recovered, secret) {
+			t.Errorf("shares %v recovered a different secret", indices)
+		}
+	}
+}
+
+func TestShamir_wrongShares(t *testing.T) {
+	secret := []byte("top secret")
+	shares, err := splitSecret(secret, "ABCD", 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherShares, err := splitSecret(secret, "EF01", 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resplitShares, err := splitSecret(secret, "ABCD", 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range map[string]struct {
+		shares   []keyShare
+		expected string
+	}{
+		"none":            {shares: nil, expected: "no shares given"},
+		"too few":         {shares: shares[:1], expected: "1 shares given, but 2 are needed"},
+		"other key":       {shares: []keyShare{shares[0], otherShares[1]}, expected: "shares belong to different keys"},
+		"duplicate":       {shares: []keyShare{shares[1], shares[1]}, expected: "share 2 is given twice"},
+		"other length":    {shares: []keyShare{shares[0], {Fingerprint: "ABCD", Threshold: 2, X: 2, Y: shares[1].Y[1:]}}, expected: "shares belong to different keys"},
+		"other threshold": {shares: []keyShare{shares[0], {Fingerprint: "ABCD", Threshold: 3, X: 2, Y: shares[1].Y}}, expected: "shares belong to different keys"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := combineShares(test.shares); err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("expected error containing %q, got %v", test.expected, err)
+			}
+		})
+	}
+
+	// Shares carry no checksum, so shares of different splits or corrupted shares recover a wrong secret. The
+	// combine_shares function detects this by checking the fingerprint of the recovered key.
+	corrupted := keyShare{Fingerprint: "ABCD", Threshold: 2, X: shares[1].X, Y: bytes.Clone(shares[1].Y)}
+	corrupted.Y[0] ^= 1
+	for name, pair := range map[string][]keyShare{
+		"other split": {shares[0], resplitShares[1]},
+		"corrupted":   {shares[0], corrupted},
+	} {
+		recovered, err := combineShares(pair)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if bytes.Equal(recovered, secret) {
+			t.Errorf("%s: expected a wrong secret", name)
+		}
+	}
+}
+
+func TestSplitSecret_invalid(t *testing.T) {
+	for _, test := range []struct{ n, threshold int }{{3, 1}, {2, 3}, {256, 2}} {
+		if _, err := splitSecret([]byte("top secret"), "ABCD", test.n, test.threshold); err == nil {
+			t.Errorf("expected an error for %d shares with threshold %d", test.n, test.threshold)
+		}
+	}
+}
+
+func TestParseKeyShare_invalid(t *testing.T) {
+	for share, expected := range map[string]string{
+		"gpg-share-v2:ABCD:2:1:AAAA": "not a key share",
+		"gpg-share-v1:ABCD:2:1":      "not a key share",
+		"gpg-share-v1:ABCD:x:1:AAAA": "invalid threshold",
+		"gpg-share-v1:ABCD:2:0:AAAA": "invalid share index",
+		"gpg-share-v1:ABCD:2:1:A":    "invalid share data",
+	} {
+		if _, err := parseKeyShare(share); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("parseKeyShare(%q): expected error containing %q, got %v", share, expected, err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	goed25519 "github.com/ProtonMail/go-crypto/openpgp/ed25519"
+	"github.com/ProtonMail/go-crypto/openpgp/eddsa"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"golang.org/x/crypto/ssh"
+	"strings"
+	"time"
+)
+
+// errNoAuthenticationKey is returned if a key has no valid authentication-capable primary key or subkey.
+var errNoAuthenticationKey = errors.New("key has no valid authentication key")
+
+// authenticationKey returns the newest valid authentication-capable subkey of the entity, or its primary key if that is
+// authentication-capable and no such subkey exists. The private key is nil if the entity has no secret key material.
+func authenticationKey(entity *openpgp.Entity) (*packet.PublicKey, *packet.PrivateKey, error) {
+	now := time.Now()
+
+	var publicKey *packet.PublicKey
+	var privateKey *packet.PrivateKey
+	for _, subkey := range entity.Subkeys {
+		sig, err := subkey.Verify(now, nil)
+		if err != nil || !sig.FlagsValid || !sig.FlagAuthenticate {
+			continue
+		}
+		if publicKey == nil || subkey.PublicKey.CreationTime.After(publicKey.CreationTime) {
+			publicKey, privateKey = subkey.PublicKey, subkey.PrivateKey
+		}
+	}
+	if publicKey != nil {
+		return publicKey, privateKey, nil
+	}
+
+	sig, err := entity.VerifyPrimaryKey(now, nil)
+	if err == nil && sig.FlagsValid && sig.FlagAuthenticate {
+		return entity.PrimaryKey, entity.PrivateKey, nil
+	}
+	return nil, nil, errNoAuthenticationKey
+}
+
+// sshPublicKey converts an OpenPGP public key into its SSH representation. Ed25519 and RSA keys are supported.
+func sshPublicKey(publicKey *packet.PublicKey) (ssh.PublicKey, error) {
+	switch key := publicKey.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return ssh.NewPublicKey(key)
+	case *eddsa.PublicKey:
+		if len(key.X) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported EdDSA curve")
+		}
+		return ssh.NewPublicKey(ed25519.PublicKey(key.X))
+	case *goed25519.PublicKey:
+		return ssh.NewPublicKey(ed25519.PublicKey(key.Point))
+	default:
+		return nil, fmt.Errorf("unsupported public key algorithm %d", publicKey.PubKeyAlgo)
+	}
+}
+
+// sshAuthorizedKey returns the authorized_keys line of the authentication key of the entity. Like
+// `gpg --export-ssh-key` the comment is the short key ID of the authentication key.
+func sshAuthorizedKey(entity *openpgp.Entity) (string, error) {
+	publicKey, _, err := authenticationKey(entity)
+	if err != nil {
+		return "", err
+	}
+	sshKey, err := sshPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	line := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(sshKey)), "\n")
+	return fmt.Sprintf("%s openpgp:0x%08X", line, uint32(publicKey.KeyId)), nil
+}
+
+// sshKeyAlgorithmSupported returns an error if keys generated with the config cannot be converted into SSH keys.
+func sshKeyAlgorithmSupported(config *packet.Config) error {
+	switch config.PublicKeyAlgorithm() {
+	case packet.PubKeyAlgoRSA, packet.PubKeyAlgoEd25519:
+		return nil
+	case packet.PubKeyAlgoEdDSA:
+		if config.CurveName() == packet.Curve25519 {
+			return nil
+		}
+		return fmt.Errorf("unsupported EdDSA curve %s", config.CurveName())
+	case packet.PubKeyAlgoEd448:
+		return errors.New("Ed448 keys are not supported")
+	default:
+		return fmt.Errorf("unsupported public key algorithm %d", config.PublicKeyAlgorithm())
+	}
+}
+
+// addAuthenticationSubkey adds an authentication-only subkey to the entity, using the key algorithm of the config.
+func addAuthenticationSubkey(entity *openpgp.Entity, config *packet.Config) error {
+	if err := entity.AddSigningSubkey(config); err != nil {
+		return err
+	}
+	subkey := &entity.Subkeys[len(entity.Subkeys)-1]
+	sig := subkey.Bindings[len(subkey.Bindings)-1].Packet
+	sig.FlagSign = false
+	sig.FlagAuthenticate = true
+	sig.EmbeddedSignature = nil
+	return sig.SignKey(subkey.PublicKey, entity.PrivateKey, config)
+}
+
+// sshPrivateKey converts an unlocked OpenPGP private key into an OpenSSH private key in PEM format. The key is
+// encrypted with bcrypt-pbkdf if the passphrase is not empty. Ed25519 and RSA keys are supported.
+func sshPrivateKey(privateKey *packet.PrivateKey, passphrase []byte) (string, error) {
+	if privateKey == nil || privateKey.Encrypted {
+		return "", errors.New("private key is not available or locked")
+	}
+
+	var key any
+	switch raw := privateKey.PrivateKey.(type) {
+	case *rsa.PrivateKey:
+		key = raw
+	case *eddsa.PrivateKey:
+		if len(raw.D) != ed25519.SeedSize {
+			return "", fmt.Errorf("unsupported EdDSA curve")
+		}
+		key = ed25519.NewKeyFromSeed(raw.D)
+	case *goed25519.PrivateKey:
+		key = ed25519.NewKeyFromSeed(raw.Key[:ed25519.SeedSize])
+	default:
+		return "", fmt.Errorf("unsupported public key algorithm %d", privateKey.PubKeyAlgo)
+	}
+
+	comment := fmt.Sprintf("openpgp:0x%08X", uint32(privateKey.KeyId))
+
+	var block *pem.Block
+	var err error
+	if len(passphrase) > 0 {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, comment, passphrase)
+	} else {
+		block, err = ssh.MarshalPrivateKey(key, comment)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(block)), nil
+}
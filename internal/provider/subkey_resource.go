@@ -0,0 +1,435 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"github.com/ProtonMail/gopenpgp/v3/armor"
+	"github.com/ProtonMail/gopenpgp/v3/constants"
+	"github.com/ProtonMail/gopenpgp/v3/profile"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &SubkeyResource{}
+var _ resource.ResourceWithConfigure = &SubkeyResource{}
+var _ resource.ResourceWithValidateConfig = &SubkeyResource{}
+
+// subkeyUsages are the supported capabilities of a subkey.
+var subkeyUsages = []string{"encrypt", "sign", "authenticate"}
+
+func NewSubkeyResource() resource.Resource {
+	return &SubkeyResource{}
+}
+
+type SubkeyResource struct {
+	// defaults holds the provider configuration, it is nil if the provider has not been configured.
+	defaults *GpgProviderModel
+}
+
+func (g *SubkeyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	defaults, ok := req.ProviderData.(*GpgProviderModel)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected *GpgProviderModel, got %T.", req.ProviderData))
+		return
+	}
+	g.defaults = defaults
+}
+
+func (g SubkeyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_subkey"
+}
+
+func (g SubkeyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A resource for adding a subkey to an existing key, which allows rotating subkeys without changing " +
+			"the fingerprint of the key. On destroy, the subkey is revoked as retired at the time of the destroy with the " +
+			"primary key and `passphrase` from the state, so that signatures made before stay valid. The public key with " +
+			"the revocation is uploaded to `keyserver`. Without `keyserver`, the destroy fails with the revocation in the " +
+			"error and the subkey stays in the state until it is removed with `terraform state rm`.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprint of the subkey.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"primary_private_key": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private key in armored format to which the subkey is added. The secret key material of the primary key is required to bind the subkey. Only another primary key forces a new subkey, not another armor, e.g. with another comment.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIf(func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+						// An unknown key is only known during apply, when the update can no longer become a replacement.
+						resp.RequiresReplace = req.PlanValue.IsUnknown() || !samePrimaryKey(req.StateValue.ValueString(), req.PlanValue.ValueString())
+					}, "Another primary key forces a new subkey.", "Another primary key forces a new subkey."),
+				},
+			},
+			"passphrase": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Passphrase for unlocking the primary key, which also protects the subkey.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"usage": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: fmt.Sprintf("Capability of the subkey, one of %q. Signing subkeys carry a back-signature. Defaults to `encrypt`.", subkeyUsages),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"expiry": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Expiry of the subkey as duration, e.g. `8760h`. Defaults to a subkey that does not expire.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"keyserver": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Keyserver to which the public key with the subkey revoked is uploaded when the subkey is destroyed, e.g. `hkps://keys.openpgp.org`. Required to destroy the subkey.",
+			},
+			"fingerprint": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprint of the subkey.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"primary_fingerprint": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprint of the primary key.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key in armored format including the subkey.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"private_key": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Private key in armored format including the subkey.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (g SubkeyResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data subkeyModelV1
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Usage.IsNull() && !data.Usage.IsUnknown() && !slices.Contains(subkeyUsages, data.Usage.ValueString()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("usage"),
+			"Invalid subkey usage",
+			fmt.Sprintf("Unknown subkey usage %q, expected one of %q.", data.Usage.ValueString(), subkeyUsages),
+		)
+	}
+	if !data.Expiry.IsNull() && !data.Expiry.IsUnknown() {
+		if _, err := parseExpiry(data.Expiry.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("expiry"), "Invalid expiry", err.Error())
+		}
+	}
+	if !data.Keyserver.IsNull() && !data.Keyserver.IsUnknown() {
+		if _, err := hkpBaseURL(data.Keyserver.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("keyserver"), "Invalid keyserver", err.Error())
+		}
+	}
+}
+
+func (g SubkeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data subkeyModelV1
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var defaults GpgProviderModel
+	if g.defaults != nil {
+		defaults = *g.defaults
+	}
+
+	keyProfile, err := profileByName(resolveString(types.StringNull(), defaults.DefaultProfile, defaultProfileName).ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("GPG subkey generation failed", err.Error())
+		return
+	}
+	keyProfile.S2kKeyEncryption = resolveS2K(nil, defaults.DefaultS2K, keyProfile).config()
+
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(data.PrimaryPrivateKey.ValueString()))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("primary_private_key"), "GPG subkey generation failed", fmt.Sprintf("ReadArmoredKeyRing failed with error: %s", err))
+		return
+	}
+	if len(entities) != 1 || entities[0].PrivateKey == nil || entities[0].PrivateKey.Dummy() {
+		resp.Diagnostics.AddAttributeError(path.Root("primary_private_key"), "GPG subkey generation failed", "Expected exactly one private key with the secret key material of the primary key.")
+		return
+	}
+	entity := entities[0]
+
+	// Only the primary key is unlocked to bind the subkey, the locked copy is serialized.
+	locked := *entity.PrivateKey
+	passphrase := []byte(data.Passphrase.ValueString())
+	if err := entity.PrivateKey.Decrypt(passphrase); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("passphrase"), "GPG subkey generation failed", fmt.Sprintf("Decrypt failed with error: %s", err))
+		return
+	}
+
+	config, err := subkeyConfig(entity.PrimaryKey, keyProfile)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("primary_private_key"), "GPG subkey generation failed", err.Error())
+		return
+	}
+	if !data.Expiry.IsNull() {
+		lifetime, err := parseExpiry(data.Expiry.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("expiry"), "GPG subkey generation failed", err.Error())
+			return
+		}
+		config.KeyLifetimeSecs = uint32(lifetime)
+	}
+
+	switch resolveString(data.Usage, types.StringNull(), "encrypt").ValueString() {
+	case "sign":
+		err = entity.AddSigningSubkey(config)
+	case "authenticate":
+		err = addAuthenticationSubkey(entity, config)
+	default:
+		err = entity.AddEncryptionSubkey(config)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("GPG subkey generation failed", fmt.Sprintf("Adding the subkey failed with error: %s", err))
+		return
+	}
+	subkey := &entity.Subkeys[len(entity.Subkeys)-1]
+	if len(passphrase) > 0 {
+		if err := subkey.PrivateKey.EncryptWithConfig(passphrase, keyProfile.KeyEncryptionConfig()); err != nil {
+			resp.Diagnostics.AddError("GPG subkey generation failed", fmt.Sprintf("EncryptWithConfig failed with error: %s", err))
+			return
+		}
+	}
+
+	comment := resolveString(types.StringNull(), defaults.DefaultComment, "").ValueString()
+
+	entity.PrivateKey = &locked
+	var privateKey bytes.Buffer
+	if err := entity.SerializePrivateWithoutSigning(&privateKey, nil); err != nil {
+		resp.Diagnostics.AddError("GPG subkey generation failed", fmt.Sprintf("SerializePrivateWithoutSigning failed with error: %s", err))
+		return
+	}
+	armoredPrivateKey, err := armorPrivateKey(entity, privateKey.Bytes(), comment)
+	if err != nil {
+		resp.Diagnostics.AddError("GPG subkey generation failed", fmt.Sprintf("Armor failed with error: %s", err))
+		return
+	}
+	armoredPublicKey, err := armorPublicKey(entity, entity.PrimaryKey.Version, comment)
+	if err != nil {
+		resp.Diagnostics.AddError("GPG subkey generation failed", fmt.Sprintf("Armor failed with error: %s", err))
+		return
+	}
+
+	data.Id = types.StringValue(hex.EncodeToString(subkey.PublicKey.Fingerprint))
+	data.Fingerprint = data.Id
+	data.PrimaryFingerprint = types.StringValue(hex.EncodeToString(entity.PrimaryKey.Fingerprint))
+	data.PublicKey = types.StringValue(armoredPublicKey)
+	data.PrivateKey = types.StringValue(armoredPrivateKey)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (g SubkeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Nothing to do here.
+}
+
+// Update ensures the plan value is copied to the state to complete the update.
+func (g SubkeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var model subkeyModelV1
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &model)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &model)...)
+}
+
+// Delete revokes the subkey and publishes the revocation to the keyserver. If no keyserver is set, it fails with the
+// revocation, so that the subkey is not dropped from the state before the revocation is published.
+func (g SubkeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data subkeyModelV1
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var defaults GpgProviderModel
+	if g.defaults != nil {
+		defaults = *g.defaults
+	}
+
+	keyProfile, err := profileByName(resolveString(types.StringNull(), defaults.DefaultProfile, defaultProfileName).ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("GPG subkey revocation failed", err.Error())
+		return
+	}
+	config := keyProfile.KeyGenerationConfig(constants.HighSecurity)
+	config.Time = time.Now
+
+	comment := resolveString(types.StringNull(), defaults.DefaultComment, "").ValueString()
+	revokedPublicKey, err := revokeSubkey(data.PrivateKey.ValueString(), []byte(data.Passphrase.ValueString()), data.Fingerprint.ValueString(), comment, config)
+	if err != nil {
+		resp.Diagnostics.AddError("GPG subkey revocation failed", fmt.Sprintf("Revocation of %s failed with error: %s", data.Fingerprint.ValueString(), err))
+		return
+	}
+
+	if data.Keyserver.IsNull() {
+		resp.Diagnostics.AddError(
+			"GPG subkey revocation not published",
+			fmt.Sprintf("The subkey %s has no keyserver to publish its revocation to. Set keyserver, or publish the revocation and remove the subkey with terraform state rm:\n\n%s", data.Fingerprint.ValueString(), revokedPublicKey),
+		)
+		return
+	}
+
+	if err := hkpAdd(ctx, data.Keyserver.ValueString(), revokedPublicKey); err != nil {
+		resp.Diagnostics.AddError("GPG subkey revocation failed", fmt.Sprintf("Upload of the revocation of %s failed with error: %s", data.Fingerprint.ValueString(), err))
+	}
+}
+
+type subkeyModelV1 struct {
+	Id                 types.String `tfsdk:"id"`
+	PrimaryPrivateKey  types.String `tfsdk:"primary_private_key"`
+	Passphrase         types.String `tfsdk:"passphrase"`
+	Usage              types.String `tfsdk:"usage"`
+	Expiry             types.String `tfsdk:"expiry"`
+	Keyserver          types.String `tfsdk:"keyserver"`
+	Fingerprint        types.String `tfsdk:"fingerprint"`
+	PrimaryFingerprint types.String `tfsdk:"primary_fingerprint"`
+	PublicKey          types.String `tfsdk:"public_key"`
+	PrivateKey         types.String `tfsdk:"private_key"`
+}
+
+// samePrimaryKey returns whether the armored private keys contain the same primary key with the same key material,
+// regardless of their armor headers.
+func samePrimaryKey(a string, b string) bool {
+	serialize := func(armored string) ([]byte, bool) {
+		entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+		if err != nil || len(entities) != 1 || entities[0].PrivateKey == nil {
+			return nil, false
+		}
+		var buf bytes.Buffer
+		if err := entities[0].PrivateKey.Serialize(&buf); err != nil {
+			return nil, false
+		}
+		return buf.Bytes(), true
+	}
+	first, ok := serialize(a)
+	if !ok {
+		return false
+	}
+	second, ok := serialize(b)
+	return ok && bytes.Equal(first, second)
+}
+
+// subkeyConfig returns the key generation config of the profile for a subkey matching the algorithm and version of the
+// primary key. Encryption subkeys of signing algorithms use the corresponding key agreement algorithm.
+func subkeyConfig(primary *packet.PublicKey, p *profile.Custom) (*packet.Config, error) {
+	config := p.KeyGenerationConfig(constants.HighSecurity)
+	config.V6Keys = primary.Version == 6
+	config.Time = time.Now
+
+	switch primary.PubKeyAlgo {
+	case packet.PubKeyAlgoRSA, packet.PubKeyAlgoRSASignOnly:
+		bits, err := primary.BitLength()
+		if err != nil {
+			return nil, err
+		}
+		config.Algorithm = packet.PubKeyAlgoRSA
+		config.RSABits = int(bits)
+	case packet.PubKeyAlgoEdDSA:
+		config.Algorithm = packet.PubKeyAlgoEdDSA
+		config.Curve = packet.Curve25519
+	case packet.PubKeyAlgoEd25519, packet.PubKeyAlgoEd448:
+		config.Algorithm = primary.PubKeyAlgo
+	default:
+		return nil, fmt.Errorf("unsupported public key algorithm %d of the primary key", primary.PubKeyAlgo)
+	}
+	return config, nil
+}
+
+// revokeSubkey revokes the subkey with the fingerprint of the armored private key as retired at the time of the config
+// and returns the armored public key with the revocation. The primary key is unlocked with the passphrase to sign the
+// revocation.
+func revokeSubkey(privateKey string, passphrase []byte, fingerprint string, comment string, config *packet.Config) (string, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(privateKey))
+	if err != nil {
+		return "", err
+	}
+	if len(entities) != 1 || entities[0].PrivateKey == nil {
+		return "", fmt.Errorf("expected exactly one private key")
+	}
+	entity := entities[0]
+	if err := entity.PrivateKey.Decrypt(passphrase); err != nil {
+		return "", err
+	}
+
+	for i := range entity.Subkeys {
+		subkey := &entity.Subkeys[i]
+		if hex.EncodeToString(subkey.PublicKey.Fingerprint) != fingerprint {
+			continue
+		}
+		if err := subkey.Revoke(packet.KeyRetired, "", config); err != nil {
+			return "", err
+		}
+		return armorPublicKey(entity, entity.PrimaryKey.Version, comment)
+	}
+	return "", fmt.Errorf("subkey %s not found", fingerprint)
+}
+
+// armorPublicKey armors the public key of an entity of either openpgp package like the keys of the key pair resource,
+// with a checksum for keys other than version 6.
+func armorPublicKey(entity interface{ Serialize(w io.Writer) error }, version int, comment string) (string, error) {
+	var buf bytes.Buffer
+	if err := entity.Serialize(&buf); err != nil {
+		return "", err
+	}
+	return armor.ArmorWithTypeAndCustomHeadersChecksum(buf.Bytes(), constants.PublicKeyHeader, "", comment, version != 6)
+}
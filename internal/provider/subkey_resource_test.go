@@ -0,0 +1,404 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"github.com/ProtonMail/gopenpgp/v3/armor"
+	"github.com/ProtonMail/gopenpgp/v3/constants"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestAccSubkeyResource(t *testing.T) {
+	keyserver := newTestKeyserver()
+	defer keyserver.Close()
+	signingKeyserver := newTestKeyserver()
+	defer signingKeyserver.Close()
+
+	var primaryFingerprint, subkeyFingerprint string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(s *terraform.State) error {
+			keyserver.mu.Lock()
+			defer keyserver.mu.Unlock()
+			published, ok := keyserver.keys[strings.ToUpper(primaryFingerprint)]
+			if !ok {
+				return fmt.Errorf("the revocation of the subkey has not been published")
+			}
+			return testAccCheckSubkeyRevoked(published, subkeyFingerprint)
+		},
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccSubkeyResourceConfig(keyserver.URL, signingKeyserver.URL, ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("gpg_subkey.signing", "primary_fingerprint", "gpg_key_pair.test", "fingerprint"),
+					resource.TestCheckResourceAttrPair("gpg_subkey.signing", "id", "gpg_subkey.signing", "fingerprint"),
+					testAccCheckGpgSubkey("gpg_subkey.signing", "sign", filepath.Join(t.TempDir(), "gnupg")),
+					testAccCheckGpgSubkey("gpg_subkey.encryption", "encrypt", filepath.Join(t.TempDir(), "gnupg")),
+					func(s *terraform.State) error {
+						rs := s.RootModule().Resources["gpg_subkey.encryption"]
+						primaryFingerprint = rs.Primary.Attributes["primary_fingerprint"]
+						subkeyFingerprint = rs.Primary.Attributes["fingerprint"]
+						return nil
+					},
+				),
+			},
+			// Update testing, the key re-armored with a comment keeps the subkeys
+			{
+				Config: testAccSubkeyResourceConfig(keyserver.URL, signingKeyserver.URL, "managed by terraform"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_key_pair.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectResourceAction("gpg_subkey.signing", plancheck.ResourceActionUpdate),
+						plancheck.ExpectResourceAction("gpg_subkey.encryption", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPtr("gpg_subkey.encryption", "fingerprint", &subkeyFingerprint),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccSubkeyResource_withoutKeyserver(t *testing.T) {
+	keyserver := newTestKeyserver()
+	defer keyserver.Close()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccSubkeyResourceProviderDefaultsConfig(""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgSubkey("gpg_subkey.signing", "sign", ""),
+				),
+			},
+			// Delete testing, the subkey is kept until its revocation can be published
+			{
+				Config:      testAccSubkeyResourceProviderDefaultsConfig(""),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile(`no keyserver to publish its revocation to`),
+			},
+			// Update testing, setting the keyserver keeps the subkey
+			{
+				Config: testAccSubkeyResourceProviderDefaultsConfig(keyserver.URL),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("gpg_subkey.signing", plancheck.ResourceActionUpdate),
+					},
+				},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccSubkeyResource_providerDefaults(t *testing.T) {
+	keyserver := newTestKeyserver()
+	defer keyserver.Close()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccSubkeyResourceProviderDefaultsConfig(keyserver.URL),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgKeyPairVersion("gpg_subkey.signing", 6),
+					testAccCheckGpgSubkey("gpg_subkey.signing", "sign", ""),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+// testAccCheckGpgSubkey checks that the subkey is bound to the primary key with the expected capability and a
+// back-signature for signing subkeys and that the private key contains the subkey locked with the passphrase. If gpg is
+// installed and the key is version 4, it also checks that gpg uses the subkey.
+func testAccCheckGpgSubkey(name string, usage string, home string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+		fingerprint := rs.Primary.Attributes["fingerprint"]
+		passphrase := rs.Primary.Attributes["passphrase"]
+
+		keys, err := openpgp.ReadArmoredKeyRing(strings.NewReader(rs.Primary.Attributes["public_key"]))
+		if err != nil {
+			return err
+		}
+		subkey, err := testAccFindSubkey(keys, fingerprint)
+		if err != nil {
+			return err
+		}
+		sig, err := subkey.Verify(time.Now(), nil)
+		if err != nil {
+			return err
+		}
+		switch usage {
+		case "sign":
+			if !sig.FlagSign || sig.EmbeddedSignature == nil {
+				return fmt.Errorf("expected a signing subkey with back-signature")
+			}
+		case "encrypt":
+			if !sig.FlagEncryptCommunications || !sig.FlagEncryptStorage {
+				return fmt.Errorf("expected an encryption subkey")
+			}
+		}
+		if subkey.Revoked(sig, time.Now()) {
+			return fmt.Errorf("subkey %s is revoked", fingerprint)
+		}
+
+		privateKeys, err := openpgp.ReadArmoredKeyRing(strings.NewReader(rs.Primary.Attributes["private_key"]))
+		if err != nil {
+			return err
+		}
+		privateSubkey, err := testAccFindSubkey(privateKeys, fingerprint)
+		if err != nil {
+			return err
+		}
+		if privateSubkey.PrivateKey == nil || !privateSubkey.PrivateKey.Encrypted {
+			return fmt.Errorf("expected subkey %s to be locked", fingerprint)
+		}
+		if !privateKeys[0].PrivateKey.Encrypted {
+			return fmt.Errorf("expected the primary key to stay locked")
+		}
+		if err := privateKeys[0].DecryptPrivateKeys([]byte(passphrase)); err != nil {
+			return err
+		}
+
+		if _, err := exec.LookPath("gpg"); err != nil || keys[0].PrimaryKey.Version != 4 {
+			return nil
+		}
+		defer func() {
+			_ = exec.Command("gpgconf", "--homedir", home, "--kill", "gpg-agent").Run()
+		}()
+
+		gpg := func(stdin string, args ...string) (string, error) {
+			cmd := exec.Command("gpg", append([]string{"--homedir", home, "--batch", "--pinentry-mode", "loopback", "--passphrase", passphrase, "--trust-model", "always"}, args...)...)
+			cmd.Stdin = strings.NewReader(stdin)
+			var stderr strings.Builder
+			cmd.Stderr = &stderr
+			out, err := cmd.Output()
+			if err != nil {
+				return "", fmt.Errorf("gpg %s failed with error: %s\n%s", strings.Join(args, " "), err, stderr.String())
+			}
+			return string(out), nil
+		}
+		if err := os.MkdirAll(home, 0o700); err != nil {
+			return err
+		}
+		if _, err := gpg(rs.Primary.Attributes["private_key"], "--import"); err != nil {
+			return err
+		}
+
+		switch usage {
+		case "sign":
+			signature, err := gpg("content", "--armor", "--local-user", fingerprint+"!", "--detach-sign", "--output", "-")
+			if err != nil {
+				return err
+			}
+			signatureFile := filepath.Join(home, "content.asc")
+			if err := os.WriteFile(signatureFile, []byte(signature), 0o600); err != nil {
+				return err
+			}
+			if _, err := gpg("content", "--verify", signatureFile, "-"); err != nil {
+				return err
+			}
+		case "encrypt":
+			ciphertext, err := gpg("content", "--armor", "--recipient", fingerprint+"!", "--encrypt", "--output", "-")
+			if err != nil {
+				return err
+			}
+			if _, err := gpg(ciphertext, "--decrypt"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestRevokeSubkey(t *testing.T) {
+	// The subkey is created and signs an hour before it is revoked.
+	created := time.Now().Add(-time.Hour)
+	config := &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA, Time: func() time.Time { return created }}
+	entity, err := openpgp.NewEntity("John Doe", "", "john.doe@example.com", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.AddSigningSubkey(config); err != nil {
+		t.Fatal(err)
+	}
+	subkey := entity.Subkeys[len(entity.Subkeys)-1]
+	fingerprint := hex.EncodeToString(subkey.PublicKey.Fingerprint)
+	if err := entity.EncryptPrivateKeys([]byte("top secret"), config); err != nil {
+		t.Fatal(err)
+	}
+	var privateKey bytes.Buffer
+	if err := entity.SerializePrivateWithoutSigning(&privateKey, nil); err != nil {
+		t.Fatal(err)
+	}
+	armoredPrivateKey, err := armor.ArmorWithType(privateKey.Bytes(), constants.PrivateKeyHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := entity.DecryptPrivateKeys([]byte("top secret")); err != nil {
+		t.Fatal(err)
+	}
+	var signature bytes.Buffer
+	if err := openpgp.DetachSign(&signature, []*openpgp.Entity{entity}, strings.NewReader("content"), config); err != nil {
+		t.Fatal(err)
+	}
+	signaturePacket, err := packet.Read(&signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, ok := signaturePacket.(*packet.Signature)
+	if !ok || !bytes.Equal(sig.IssuerFingerprint, subkey.PublicKey.Fingerprint) {
+		t.Fatalf("expected a signature of subkey %s", fingerprint)
+	}
+
+	if _, err := revokeSubkey(armoredPrivateKey, []byte("wrong"), fingerprint, "", nil); err == nil {
+		t.Errorf("expected the wrong passphrase to fail")
+	}
+	revokedPublicKey, err := revokeSubkey(armoredPrivateKey, []byte("top secret"), fingerprint, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := testAccCheckSubkeyRevoked(revokedPublicKey, fingerprint); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := openpgp.ReadArmoredKeyRing(strings.NewReader(revokedPublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	revokedSubkey, err := testAccFindSubkey(keys, fingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revocation := revokedSubkey.Revocations[0].Packet
+	if *revocation.RevocationReason != packet.KeyRetired {
+		t.Errorf("expected the subkey to be retired, got reason %d", *revocation.RevocationReason)
+	}
+	if !revocation.CreationTime.After(sig.CreationTime) {
+		t.Errorf("revocation at %s does not come after the signature at %s", revocation.CreationTime, sig.CreationTime)
+	}
+}
+
+// testAccFindSubkey returns the subkey with the fingerprint of the only key of the key ring.
+func testAccFindSubkey(keys []*openpgp.Entity, fingerprint string) (*openpgp.Subkey, error) {
+	if len(keys) != 1 {
+		return nil, fmt.Errorf("expected exactly one key, got %d", len(keys))
+	}
+	for i, subkey := range keys[0].Subkeys {
+		if hex.EncodeToString(subkey.PublicKey.Fingerprint) == fingerprint {
+			return &keys[0].Subkeys[i], nil
+		}
+	}
+	return nil, fmt.Errorf("subkey %s not found", fingerprint)
+}
+
+// testAccCheckSubkeyRevoked checks that the armored public key revokes the subkey.
+func testAccCheckSubkeyRevoked(publicKey string, fingerprint string) error {
+	keys, err := openpgp.ReadArmoredKeyRing(strings.NewReader(publicKey))
+	if err != nil {
+		return err
+	}
+	subkey, err := testAccFindSubkey(keys, fingerprint)
+	if err != nil {
+		return err
+	}
+	sig, err := subkey.LatestValidBindingSignature(time.Time{}, nil)
+	if err != nil {
+		return err
+	}
+	if !subkey.Revoked(sig, time.Now()) {
+		return fmt.Errorf("subkey %s is not revoked", fingerprint)
+	}
+	return nil
+}
+
+func testAccSubkeyResourceConfig(keyserver string, signingKeyserver string, comment string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+  comment    = %[3]q
+}
+
+resource "gpg_subkey" "signing" {
+  primary_private_key = gpg_key_pair.test.private_key
+  passphrase          = gpg_key_pair.test.passphrase
+  usage               = "sign"
+  expiry              = "8760h"
+  keyserver           = %[2]q
+}
+
+resource "gpg_subkey" "encryption" {
+  primary_private_key = gpg_key_pair.test.private_key
+  passphrase          = gpg_key_pair.test.passphrase
+  keyserver           = %[1]q
+}
+`, keyserver, signingKeyserver, comment)
+}
+
+// testAccSubkeyResourceProviderDefaultsConfig returns a configuration of a signing subkey with the provider defaults,
+// without keyserver if it is empty.
+func testAccSubkeyResourceProviderDefaultsConfig(keyserver string) string {
+	keyserverAttribute := ""
+	if keyserver != "" {
+		keyserverAttribute = fmt.Sprintf("keyserver           = %q", keyserver)
+	}
+	return fmt.Sprintf(`
+provider "gpg" {
+  default_profile = "rfc9580"
+  default_s2k = {
+	mode          = "argon2"
+	argon2_passes = 1
+	argon2_memory = 1024
+  }
+}
+
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+}
+
+resource "gpg_subkey" "signing" {
+  primary_private_key = gpg_key_pair.test.private_key
+  passphrase          = gpg_key_pair.test.passphrase
+  usage               = "sign"
+  %[1]s
+}
+`, keyserverAttribute)
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	openpgp "github.com/ProtonMail/go-crypto/openpgp/v2"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"strings"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ToAgentKeysFunction{}
+
+func NewToAgentKeysFunction() function.Function {
+	return &ToAgentKeysFunction{}
+}
+
+type ToAgentKeysFunction struct{}
+
+func (f ToAgentKeysFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "to_agent_keys"
+}
+
+func (f ToAgentKeysFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Convert a private key to the key files of gpg-agent",
+		MarkdownDescription: "Returns the content of `private-keys-v1.d/<keygrip>.key` in the extended key format of gpg-agent " +
+			"for the primary key and each subkey with secret key material, by keygrip. The keys are protected with the " +
+			"passphrase using the `openpgp-s2k3-ocb-aes` protection of GnuPG 2.2 and later, or stored unprotected if the " +
+			"passphrase is empty. Terraform requires functions to return the same result for the same arguments, so the " +
+			"salt and nonce are derived from the fingerprint of the key, and the protection time is the creation time of " +
+			"the key. RSA, Ed25519 and Curve25519 keys are supported.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "private_key",
+				MarkdownDescription: "Private key in armored format.",
+			},
+			function.StringParameter{
+				Name:                "passphrase",
+				MarkdownDescription: "Passphrase for unlocking the private key, which also protects the key files.",
+			},
+		},
+		Return: function.MapReturn{ElementType: types.StringType},
+	}
+}
+
+func (f ToAgentKeysFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var armoredKey, passphrase string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &armoredKey, &passphrase))
+
+	if resp.Error != nil {
+		return
+	}
+
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredKey))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("ReadArmoredKeyRing failed with error: %s", err))
+		return
+	}
+	if len(entities) != 1 || entities[0].PrivateKey == nil {
+		resp.Error = function.NewArgumentFuncError(0, "Expected exactly one private key.")
+		return
+	}
+	entity := entities[0]
+
+	if err := entity.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("DecryptPrivateKeys failed with error: %s", err))
+		return
+	}
+
+	random := agentProtectionRand(entity.PrimaryKey.Fingerprint)
+	agentKeys := map[string]string{}
+	for _, key := range secretKeys(entity) {
+		grips, err := keygrips(key.PublicKey, nil)
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Converting the key failed with error: %s", err))
+			return
+		}
+		content, err := agentPrivateKey(key.PrivateKey, []byte(passphrase), random)
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Converting the key failed with error: %s", err))
+			return
+		}
+		agentKeys[grips[0]] = content
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, agentKeys))
+}
@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccToAgentKeysFunction(t *testing.T) {
+	home := filepath.Join(t.TempDir(), "gnupg")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccToAgentKeysFunctionConfig(home, "gpg_key_pair.test.passphrase"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckGpgAgentKeys(home, "gpg_key_pair.test", "protected-private-key"),
+					testAccCheckGpgAgentKeys(home, "gpg_key_pair.unprotected", "private-key"),
+				),
+				// gpg_home reports the agent keys written by the check as drift.
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config:      testAccToAgentKeysFunctionConfig(home, `"wrong"`),
+				ExpectError: regexp.MustCompile(`DecryptPrivateKeys failed`),
+			},
+		},
+	})
+}
+
+// testAccCheckGpgAgentKeys checks that the output of the key pair contains its keys by keygrip in the expected format
+// and, if gpg is installed, that gpg can sign with the primary key in the home directory.
+func testAccCheckGpgAgentKeys(home string, name string, format string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+		output, ok := s.RootModule().Outputs[strings.TrimPrefix(name, "gpg_key_pair.")]
+		if !ok {
+			return fmt.Errorf("could not find output for %s", name)
+		}
+		agentKeys, ok := output.Value.(map[string]interface{})
+		if !ok || len(agentKeys) != 2 {
+			return fmt.Errorf("expected 2 agent keys, got %v", output.Value)
+		}
+
+		for i := 0; i < 2; i++ {
+			grip := rs.Primary.Attributes[fmt.Sprintf("keygrips.%d", i)]
+			content, ok := agentKeys[grip].(string)
+			if !ok {
+				return fmt.Errorf("agent key %s is missing", grip)
+			}
+			if !regexp.MustCompile(`^Created: \d{8}T\d{6}\nKey: \(` + format + ` \(ecc `).MatchString(content) {
+				return fmt.Errorf("unexpected agent key %s:\n%s", grip, content)
+			}
+			if err := os.WriteFile(filepath.Join(home, gnupgPrivateKeysDir, grip+".key"), []byte(content), 0o600); err != nil {
+				return err
+			}
+		}
+
+		if _, err := exec.LookPath("gpg"); err != nil {
+			return nil
+		}
+		defer func() {
+			_ = exec.Command("gpgconf", "--homedir", home, "--kill", "gpg-agent").Run()
+		}()
+
+		sign := exec.Command("gpg", "--homedir", home, "--batch", "--pinentry-mode", "loopback", "--passphrase", rs.Primary.Attributes["passphrase"],
+			"--local-user", rs.Primary.Attributes["fingerprint"]+"!", "--detach-sign", "--output", "-")
+		sign.Stdin = strings.NewReader("content")
+		if out, err := sign.CombinedOutput(); err != nil {
+			return fmt.Errorf("gpg --detach-sign failed with error: %s\n%s", err, out)
+		}
+		return nil
+	}
+}
+
+func testAccToAgentKeysFunctionConfig(home string, passphrase string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+}
+
+resource "gpg_key_pair" "unprotected" {
+  identities = [{
+	name  = "Jane Doe"
+	email = "jane.doe@example.com"
+  }]
+  passphrase = ""
+}
+
+resource "gpg_home" "test" {
+  path        = %[1]q
+  public_keys = [gpg_key_pair.test.public_key, gpg_key_pair.unprotected.public_key]
+}
+
+output "test" {
+  value     = provider::gpg::to_agent_keys(gpg_key_pair.test.private_key, %[2]s)
+  sensitive = true
+}
+
+output "unprotected" {
+  value     = provider::gpg::to_agent_keys(gpg_key_pair.unprotected.private_key, "")
+  sensitive = true
+}
+`, home, passphrase)
+}
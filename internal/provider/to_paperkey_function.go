@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ToPaperkeyFunction{}
+
+func NewToPaperkeyFunction() function.Function {
+	return &ToPaperkeyFunction{}
+}
+
+type ToPaperkeyFunction struct{}
+
+func (f ToPaperkeyFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "to_paperkey"
+}
+
+func (f ToPaperkeyFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Extract the secret parts of a private key in paperkey format",
+		MarkdownDescription: "Returns only the secret parts of the private key and its subkeys in the text format of " +
+			"[paperkey](https://www.jabberwocky.com/software/paperkey/), with a CRC-24 checksum per line and for the " +
+			"entire data. The secret parts stay protected by the passphrase of the key. Only version 4 keys are supported.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "private_key",
+				MarkdownDescription: "Private key in armored format.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f ToPaperkeyFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var privateKey string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &privateKey))
+
+	if resp.Error != nil {
+		return
+	}
+
+	key, err := gpgcrypto.NewKeyFromArmored(privateKey)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("NewKeyFromArmored failed with error: %s", err))
+		return
+	}
+
+	secretKey, err := key.Serialize()
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("Serialize failed with error: %s", err))
+		return
+	}
+
+	paperkey, err := toPaperkey(secretKey)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Extracting the secret parts failed with error: %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, paperkey))
+}
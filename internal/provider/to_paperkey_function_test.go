@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccToPaperkeyFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccToPaperkeyFunctionConfig(`gpg_key_pair.test.paperkey`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("gpg_key_pair.test", "paperkey", regexp.MustCompile(`(?m)^# Secret portions of key [0-9A-F]{40}$`)),
+					testAccCheckOutputEqualsAttr("paperkey", "gpg_key_pair.test", "paperkey"),
+					testAccCheckGpgPrivateKeyOutput("restored", "gpg_key_pair.test"),
+				),
+			},
+			{
+				Config:      testAccToPaperkeyFunctionConfig(`replace(gpg_key_pair.test.paperkey, "/(?m)^  1: 00/", "  1: 01")`),
+				ExpectError: regexp.MustCompile(`checksum mismatch`),
+			},
+		},
+	})
+}
+
+// testAccCheckGpgPrivateKeyOutput checks that the output is the private key of the key pair, unlockable with its
+// passphrase.
+func testAccCheckGpgPrivateKeyOutput(output string, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("could not find resource at path %s", name)
+		}
+		restored, ok := s.RootModule().Outputs[output]
+		if !ok {
+			return fmt.Errorf("could not find output %s", output)
+		}
+
+		key, err := crypto.NewPrivateKeyFromArmored(restored.Value.(string), []byte(rs.Primary.Attributes["passphrase"]))
+		if err != nil {
+			return err
+		}
+		if key.GetFingerprint() != rs.Primary.Attributes["fingerprint"] {
+			return fmt.Errorf("restored key has fingerprint %s, expected %s", key.GetFingerprint(), rs.Primary.Attributes["fingerprint"])
+		}
+		return nil
+	}
+}
+
+func testAccToPaperkeyFunctionConfig(paperkey string) string {
+	return fmt.Sprintf(`
+resource "gpg_key_pair" "test" {
+  identities = [{
+	name  = "John Doe"
+	email = "john.doe@example.com"
+  }]
+  passphrase = "top secret"
+}
+
+output "paperkey" {
+  value     = provider::gpg::to_paperkey(gpg_key_pair.test.private_key)
+  sensitive = true
+}
+
+output "restored" {
+  value     = provider::gpg::from_paperkey(%[1]s, gpg_key_pair.test.public_key)
+  sensitive = true
+}
+`, paperkey)
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ToSSHPublicKeyFunction{}
+
+func NewToSSHPublicKeyFunction() function.Function {
+	return &ToSSHPublicKeyFunction{}
+}
+
+type ToSSHPublicKeyFunction struct{}
+
+func (f ToSSHPublicKeyFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "to_ssh_public_key"
+}
+
+func (f ToSSHPublicKeyFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Convert the authentication key of a GPG key to an OpenSSH public key",
+		MarkdownDescription: "Returns the OpenSSH `authorized_keys` line of the newest valid authentication-capable subkey, " +
+			"or of the primary key if it is authentication-capable and no such subkey exists. Like `gpg --export-ssh-key` " +
+			"the comment is the short key ID of the authentication key. Ed25519 and RSA keys are supported.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "key",
+				MarkdownDescription: "Public or private key in armored format.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f ToSSHPublicKeyFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var armoredKey string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &armoredKey))
+
+	if resp.Error != nil {
+		return
+	}
+
+	key, err := gpgcrypto.NewKeyFromArmored(armoredKey)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("NewKeyFromArmored failed with error: %s", err))
+		return
+	}
+
+	sshKey, err := sshAuthorizedKey(key.GetEntity())
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Converting the key failed with error: %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, sshKey))
+}
@@ -0,0 +1,225 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"os"
+	"strings"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &VerifiedChecksumsDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &VerifiedChecksumsDataSource{}
+
+func NewVerifiedChecksumsDataSource() datasource.DataSource {
+	return &VerifiedChecksumsDataSource{}
+}
+
+type VerifiedChecksumsDataSource struct {
+}
+
+func (g VerifiedChecksumsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_verified_checksums"
+}
+
+func (g VerifiedChecksumsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "A data source for reading a `SHA256SUMS` file whose detached signature is verified against pinned public keys, " +
+			"e.g. the checksums of release artifacts built by goreleaser. Reading fails on a bad signature, so that the plan fails " +
+			"before unverified hashes are used.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "SHA-256 hash of the checksums file in hex format.",
+			},
+			"checksums": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Content of the checksums file in the format of `sha256sum`, i.e. lines of the hash in hex format, two spaces or a space and `*`, and the file name. Exactly one of `checksums` and `checksums_path` must be set.",
+			},
+			"checksums_path": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path of the checksums file. Exactly one of `checksums` and `checksums_path` must be set.",
+			},
+			"signature": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Detached signature of the checksums file in armored format. Exactly one of `signature` and `signature_path` must be set.",
+			},
+			"signature_path": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path of the detached signature of the checksums file in armored or binary format. Exactly one of `signature` and `signature_path` must be set.",
+			},
+			"public_keys": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Required:            true,
+				MarkdownDescription: "Public keys in armored format of which one must have signed the checksums file.",
+			},
+			"signer_fingerprint": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Fingerprint of the key that signed the checksums file, which is the primary key or a signing subkey of one of the public keys.",
+			},
+			"hashes": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "SHA-256 hashes in lowercase hex format by file name.",
+			},
+		},
+	}
+}
+
+func (g VerifiedChecksumsDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var data verifiedChecksumsModelV1
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Checksums.IsNull() == data.ChecksumsPath.IsNull() {
+		resp.Diagnostics.AddError("Invalid checksums", "Exactly one of checksums and checksums_path must be set.")
+	}
+	if data.Signature.IsNull() == data.SignaturePath.IsNull() {
+		resp.Diagnostics.AddError("Invalid signature", "Exactly one of signature and signature_path must be set.")
+	}
+	if len(data.PublicKeys.Elements()) == 0 && !data.PublicKeys.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(path.Root("public_keys"), "Invalid public keys", "At least one public key must be set.")
+	}
+}
+
+func (g VerifiedChecksumsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data verifiedChecksumsModelV1
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	checksums, diags := readContentOrFile(data.Checksums, path.Root("checksums"), data.ChecksumsPath, path.Root("checksums_path"))
+	resp.Diagnostics.Append(diags...)
+	signature, diags := readContentOrFile(data.Signature, path.Root("signature"), data.SignaturePath, path.Root("signature_path"))
+	resp.Diagnostics.Append(diags...)
+
+	var publicKeys []string
+	resp.Diagnostics.Append(data.PublicKeys.ElementsAs(ctx, &publicKeys, false)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keyRing, err := gpgcrypto.NewKeyRing(nil)
+	if err != nil {
+		resp.Diagnostics.AddError("GPG checksum verification failed", fmt.Sprintf("NewKeyRing failed with error: %s", err))
+		return
+	}
+	for i, publicKey := range publicKeys {
+		key, err := gpgcrypto.NewKeyFromArmored(publicKey)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("public_keys").AtListIndex(i), "GPG checksum verification failed", fmt.Sprintf("NewKeyFromArmored failed with error: %s", err))
+			return
+		}
+		if err := keyRing.AddKey(key); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("public_keys").AtListIndex(i), "GPG checksum verification failed", fmt.Sprintf("AddKey failed with error: %s", err))
+			return
+		}
+	}
+
+	verifier, err := gpgcrypto.PGP().Verify().VerificationKeys(keyRing).New()
+	if err != nil {
+		resp.Diagnostics.AddError("GPG checksum verification failed", fmt.Sprintf("New failed with error: %s", err))
+		return
+	}
+
+	result, err := verifier.VerifyDetached(checksums, signature, gpgcrypto.Auto)
+	if err != nil {
+		resp.Diagnostics.AddError("GPG checksum verification failed", fmt.Sprintf("VerifyDetached failed with error: %s", err))
+		return
+	}
+	if err := result.SignatureError(); err != nil {
+		resp.Diagnostics.AddError("GPG checksum verification failed", fmt.Sprintf("The checksums are not signed by any of the public keys: %s", err))
+		return
+	}
+
+	hashes, err := parseChecksums(string(checksums))
+	if err != nil {
+		resp.Diagnostics.AddError("GPG checksum verification failed", fmt.Sprintf("Parsing the checksums failed with error: %s", err))
+		return
+	}
+
+	id := sha256.Sum256(checksums)
+	data.Id = types.StringValue(hex.EncodeToString(id[:]))
+	data.SignerFingerprint = types.StringValue(hex.EncodeToString(result.SignedByFingerprint()))
+	data.Hashes, diags = types.MapValueFrom(ctx, types.StringType, hashes)
+	resp.Diagnostics.Append(diags...)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// readContentOrFile returns the inline content if it is set, or else the content of the file at the path.
+func readContentOrFile(content types.String, contentPath path.Path, filePath types.String, filePathPath path.Path) ([]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if !content.IsNull() {
+		return []byte(content.ValueString()), diags
+	}
+
+	data, err := os.ReadFile(filePath.ValueString())
+	if err != nil {
+		diags.AddAttributeError(filePathPath, "GPG checksum verification failed", fmt.Sprintf("ReadFile failed with error: %s", err))
+		return nil, diags
+	}
+	return data, diags
+}
+
+// parseChecksums parses the output of `sha256sum`, where each line is the hash in hex format followed by two spaces,
+// or a space and `*` in binary mode, and the file name. Empty lines are ignored.
+func parseChecksums(checksums string) (map[string]string, error) {
+	hashes := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(checksums))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if text == "" {
+			continue
+		}
+		hash, name, ok := strings.Cut(text, " ")
+		if !ok || (!strings.HasPrefix(name, " ") && !strings.HasPrefix(name, "*")) || len(name) < 2 {
+			return nil, fmt.Errorf("line %d is not in the format of sha256sum", line)
+		}
+		name = name[1:]
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("line %d has no SHA-256 hash in hex format", line)
+		}
+		hash = strings.ToLower(hash)
+		if previous, ok := hashes[name]; ok && previous != hash {
+			return nil, fmt.Errorf("line %d has another hash for %s", line, name)
+		}
+		hashes[name] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+type verifiedChecksumsModelV1 struct {
+	Id                types.String `tfsdk:"id"`
+	Checksums         types.String `tfsdk:"checksums"`
+	ChecksumsPath     types.String `tfsdk:"checksums_path"`
+	Signature         types.String `tfsdk:"signature"`
+	SignaturePath     types.String `tfsdk:"signature_path"`
+	PublicKeys        types.List   `tfsdk:"public_keys"`
+	SignerFingerprint types.String `tfsdk:"signer_fingerprint"`
+	Hashes            types.Map    `tfsdk:"hashes"`
+}
@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccVerifiedChecksumsDataSource(t *testing.T) {
+	checksums := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  terraform-provider-gpg_1.0.0_linux_amd64.zip\n" +
+		"2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824 *terraform-provider-gpg_1.0.0_darwin_arm64.zip\n"
+
+	vendorKey := testAccGenerateSigningKey(t)
+	otherKey := testAccGenerateSigningKey(t)
+	vendorSignature := testAccSignDetached(t, vendorKey.key, checksums, crypto.Armor)
+	binarySignature := testAccSignDetached(t, vendorKey.key, checksums, crypto.Bytes)
+	otherSignature := testAccSignDetached(t, otherKey.key, checksums, crypto.Armor)
+
+	dir := t.TempDir()
+	checksumsPath := filepath.Join(dir, "SHA256SUMS")
+	signaturePath := filepath.Join(dir, "SHA256SUMS.sig")
+	if err := os.WriteFile(checksumsPath, []byte(checksums), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(signaturePath, []byte(binarySignature), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVerifiedChecksumsDataSourceConfig(fmt.Sprintf("checksums = %q\n  signature = %q", checksums, vendorSignature), vendorKey.public, otherKey.public),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gpg_verified_checksums.test", "hashes.%", "2"),
+					resource.TestCheckResourceAttr("data.gpg_verified_checksums.test", "hashes.terraform-provider-gpg_1.0.0_linux_amd64.zip", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"),
+					resource.TestCheckResourceAttr("data.gpg_verified_checksums.test", "hashes.terraform-provider-gpg_1.0.0_darwin_arm64.zip", "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"),
+					resource.TestCheckResourceAttr("data.gpg_verified_checksums.test", "signer_fingerprint", vendorKey.signingFingerprint),
+				),
+			},
+			{
+				Config: testAccVerifiedChecksumsDataSourceConfig(fmt.Sprintf("checksums_path = %q\n  signature_path = %q", checksumsPath, signaturePath), vendorKey.public),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gpg_verified_checksums.test", "hashes.%", "2"),
+					resource.TestCheckResourceAttr("data.gpg_verified_checksums.test", "signer_fingerprint", vendorKey.signingFingerprint),
+				),
+			},
+			{
+				Config:      testAccVerifiedChecksumsDataSourceConfig(fmt.Sprintf("checksums = %q\n  signature = %q", strings.Replace(checksums, "e3b0", "e3b1", 1), vendorSignature), vendorKey.public),
+				ExpectError: regexp.MustCompile(`The checksums are not signed by any of the public keys`),
+			},
+			{
+				Config:      testAccVerifiedChecksumsDataSourceConfig(fmt.Sprintf("checksums = %q\n  signature = %q", checksums, otherSignature), vendorKey.public),
+				ExpectError: regexp.MustCompile(`The checksums are not signed by any of the public keys`),
+			},
+			{
+				Config:      testAccVerifiedChecksumsDataSourceConfig(fmt.Sprintf("checksums = %q\n  checksums_path = %q\n  signature = %q", checksums, checksumsPath, vendorSignature), vendorKey.public),
+				ExpectError: regexp.MustCompile(`Exactly one of checksums and checksums_path must be set`),
+			},
+			{
+				Config:      testAccVerifiedChecksumsDataSourceConfig(fmt.Sprintf("checksums = %q\n  signature = %q", "not a checksum\n", testAccSignDetached(t, vendorKey.key, "not a checksum\n", crypto.Armor)), vendorKey.public),
+				ExpectError: regexp.MustCompile(`line 1 is not in the format of`),
+			},
+		},
+	})
+}
+
+type testAccSigningKey struct {
+	key                *crypto.Key
+	public             string
+	signingFingerprint string
+}
+
+// testAccGenerateSigningKey generates a key and determines the fingerprint of the key that makes its signatures.
+func testAccGenerateSigningKey(t *testing.T) testAccSigningKey {
+	key, err := crypto.PGP().KeyGeneration().AddUserId("Release Bot", "release@example.com").New().GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := crypto.PGP().Verify().VerificationKey(key).New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := verifier.VerifyDetached(nil, []byte(testAccSignDetached(t, key, "", crypto.Bytes)), crypto.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testAccSigningKey{key: key, public: public, signingFingerprint: fmt.Sprintf("%x", result.SignedByFingerprint())}
+}
+
+// testAccSignDetached returns the detached signature of the content by the key in the encoding.
+func testAccSignDetached(t *testing.T, key *crypto.Key, content string, encoding int8) string {
+	signer, err := crypto.PGP().Sign().SigningKey(key).Detached().New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := signer.Sign([]byte(content), encoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(signature)
+}
+
+func testAccVerifiedChecksumsDataSourceConfig(attributes string, publicKeys ...string) string {
+	return fmt.Sprintf(`
+data "gpg_verified_checksums" "test" {
+  %[1]s
+  public_keys = %[2]s
+}
+`, attributes, testAccHCLStringList(publicKeys))
+}
+
+// testAccHCLStringList formats the strings as an HCL list.
+func testAccHCLStringList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", value))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
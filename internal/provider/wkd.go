@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	gpgcrypto "github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"net/url"
+	"strings"
+)
+
+// zbase32 is the z-base-32 encoding used for the hashed local parts of the Web Key Directory.
+var zbase32 = base32.NewEncoding("ybndrfg8ejkmcpqxot1uwisza345h769").WithPadding(base32.NoPadding)
+
+// wkdEntryAttrTypes are the attribute types of wkdEntryModelV1.
+var wkdEntryAttrTypes = map[string]attr.Type{
+	"email": types.StringType,
+	"hash":  types.StringType,
+	"url":   types.StringType,
+}
+
+type wkdEntryModelV1 struct {
+	Email types.String `tfsdk:"email"`
+	Hash  types.String `tfsdk:"hash"`
+	URL   types.String `tfsdk:"url"`
+}
+
+// validateWKDBase checks that the base URL of a Web Key Directory is an absolute HTTP or HTTPS URL.
+func validateWKDBase(base string) error {
+	u, err := url.Parse(base)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("expected an absolute https or http URL, got %q", base)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("the URL %q must not have a query or fragment", base)
+	}
+	return nil
+}
+
+// wkdHash returns the z-base-32 encoded SHA-1 hash of the lowercased local part of an email address, which is the
+// file name of the key in the `hu` directory of the Web Key Directory.
+func wkdHash(localPart string) string {
+	hash := sha1.Sum([]byte(strings.ToLower(localPart)))
+	return zbase32.EncodeToString(hash[:])
+}
+
+// wkdEntriesFromHex returns the Web Key Directory entries of the email addresses of a public key in hex format below
+// the base URL, or null if the base URL is empty.
+func wkdEntriesFromHex(ctx context.Context, publicKeyHex string, base string) (types.List, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	entriesType := types.ObjectType{AttrTypes: wkdEntryAttrTypes}
+
+	if base == "" {
+		return types.ListNull(entriesType), diags
+	}
+
+	publicKey, err := hex.DecodeString(publicKeyHex)
+	if err != nil {
+		diags.AddError("GPG WKD entry generation failed", fmt.Sprintf("DecodeString failed with error: %s", err))
+		return types.ListNull(entriesType), diags
+	}
+
+	key, err := gpgcrypto.NewKey(publicKey)
+	if err != nil {
+		diags.AddError("GPG WKD entry generation failed", fmt.Sprintf("NewKey failed with error: %s", err))
+		return types.ListNull(entriesType), diags
+	}
+
+	emails := publishableEmails(key.GetEntity())
+	entries := make([]wkdEntryModelV1, 0, len(emails))
+	for _, email := range emails {
+		at := strings.LastIndex(email, "@")
+		if at <= 0 {
+			continue
+		}
+		hash := wkdHash(email[:at])
+		entries = append(entries, wkdEntryModelV1{
+			Email: types.StringValue(email),
+			Hash:  types.StringValue(hash),
+			URL:   types.StringValue(strings.TrimSuffix(base, "/") + "/hu/" + hash + "?l=" + url.QueryEscape(email[:at])),
+		})
+	}
+
+	list, listDiags := types.ListValueFrom(ctx, entriesType, entries)
+	diags.Append(listDiags...)
+	return list, diags
+}